@@ -6,11 +6,5 @@ import (
 )
 
 func main() {
-	must(Run(&OS{}))
-}
-
-func must(err error) {
-	if err != nil {
-		os.Exit(1)
-	}
+	os.Exit(ExitCode(Run(&OS{})))
 }
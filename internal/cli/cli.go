@@ -1,7 +1,29 @@
 package cli
 
+import (
+	"errors"
+	"fmt"
+	"github.com/jessevdk/go-flags"
+	"github.com/timotto/semver-bumper/pkg/bumper"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	"strconv"
+	"strings"
+)
+
+const (
+	ExitCodeOk               = 0
+	ExitCodeError            = 1
+	ExitCodeUsage            = 2
+	ExitCodeNothingToRelease = 3
+)
+
 func Run(os Os) error {
 	if err := run(os); err != nil {
+		if isHelp(err) {
+			must(fmt.Fprint(os.Stdout(), err.Error()))
+			return nil
+		}
+
 		Errln(os, err.Error())
 		return err
 	}
@@ -9,11 +31,61 @@ func Run(os Os) error {
 	return nil
 }
 
+// ExitCode returns the process exit code for the result of Run
+func ExitCode(err error) int {
+	var flagsErr *flags.Error
+	switch {
+	case err == nil:
+		return ExitCodeOk
+	case errors.As(err, &flagsErr):
+		return ExitCodeUsage
+	case errors.Is(err, bumper.ErrNothingToRelease):
+		return ExitCodeNothingToRelease
+	default:
+		return ExitCodeError
+	}
+}
+
 func run(os Os) error {
-	rt, err := newRuntime(os)
+	if isConcourse(os) {
+		return runConcourse(os)
+	}
+
+	a := &app{os: os, opts: &Options{}}
+	_, err := newParser(a).ParseArgs(os.Args()[1:])
+
+	return err
+}
+
+func isHelp(err error) bool {
+	var flagsErr *flags.Error
+	return errors.As(err, &flagsErr) && flagsErr.Type == flags.ErrHelp
+}
+
+// validateConfigFromEnv looks at the single variable only, so that it works even if other variables are invalid
+func validateConfigFromEnv(os Os) bool {
+	value, _ := strconv.ParseBool(getenv(os, EnvName("validate-config")))
+	return value
+}
+
+func getenv(os Os, key string) string {
+	prefix := key + "="
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, prefix) {
+			return strings.TrimPrefix(kv, prefix)
+		}
+	}
+
+	return ""
+}
+
+func printConfig(os Os, opts *Options, format string) error {
+	data, err := opts.EncodeEffective(format)
 	if err != nil {
 		return err
 	}
 
-	return rt.run()
+	must(os.Stdout().Write(data))
+
+	return nil
 }
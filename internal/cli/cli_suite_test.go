@@ -5,6 +5,7 @@ import (
 	. "github.com/timotto/semver-bumper/internal/cli/clifakes"
 	"os"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -15,6 +16,9 @@ func TestCli(t *testing.T) {
 	RunSpecs(t, "Cli Suite")
 }
 
+// testNow is the date of calendar versions in the tests
+var testNow = time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC)
+
 type outputRecorder struct {
 	Stdout bytes.Buffer
 	Stderr bytes.Buffer
@@ -23,6 +27,7 @@ type outputRecorder struct {
 func newRecordingFakeOs(osArgs ...string) (*FakeOs, *outputRecorder) {
 	f := &FakeOs{}
 	f.ArgsReturns(append([]string{os.Args[0]}, osArgs...))
+	f.NowReturns(testNow)
 
 	r := &outputRecorder{}
 	f.StdoutReturns(&r.Stdout)
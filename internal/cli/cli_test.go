@@ -9,11 +9,13 @@ import (
 	. "github.com/onsi/gomega"
 	. "github.com/timotto/semver-bumper/internal/cli"
 	. "github.com/timotto/semver-bumper/internal/cli/clifakes"
+	"github.com/timotto/semver-bumper/pkg/bumper"
 	. "github.com/timotto/semver-bumper/pkg/config"
 	. "github.com/timotto/semver-bumper/pkg/test/git_testbed"
 	"gopkg.in/yaml.v3"
 	"os"
 	"path"
+	"reflect"
 	"strings"
 )
 
@@ -39,6 +41,12 @@ var _ = Describe("Run", func() {
 		return Run(fakeOs)
 	}
 
+	var runWithEnv = func(environ []string, args ...string) error {
+		fakeOs, rec = newRecordingFakeOs(args...)
+		fakeOs.EnvironReturns(environ)
+		return Run(fakeOs)
+	}
+
 	It("prints the result to stdout", func() {
 		err := runWithArgs(bed.Path(), "-0", "3.14.159")
 
@@ -65,16 +73,304 @@ var _ = Describe("Run", func() {
 		It("prints the configured version bump level keywords", func() {
 			err := runWithArgs(bed.Path(), "--print-keywords", "-1", "keyword-a", "-2", "keyword-b", "-3", "keyword-c")
 
+			Expect(err).ToNot(HaveOccurred())
+
+			stdout := rec.Stdout.String()
+			Expect(stdout).To(ContainSubstring("keywords"))
+			Expect(stdout).To(ContainSubstring("major"))
+			Expect(stdout).To(ContainSubstring("minor"))
+			Expect(stdout).To(ContainSubstring("patch"))
+			Expect(stdout).To(ContainSubstring("keyword-a"))
+			Expect(stdout).To(ContainSubstring("keyword-b"))
+			Expect(stdout).To(ContainSubstring("keyword-c"))
+		})
+	})
+
+	Describe("--explain", func() {
+		It("prints the commits with their bump level to stderr", func() {
+			bed.
+				AddCommits("initial").
+				AddLightweightTag("1.0.0").
+				AddCommits("feat: expected feature", "unexpected level")
+
+			err := runWithArgs(bed.Path(), "--explain")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("1.1.0\n"))
+			Expect(rec.Stderr.String()).To(ContainSubstring("minor"))
+			Expect(rec.Stderr.String()).To(ContainSubstring("feat: expected feature"))
+			Expect(rec.Stderr.String()).To(ContainSubstring("none  "))
+		})
+
+		It("prints the detected Go API changes to stderr", func() {
+			bed.
+				AddCommitWithContent("initial", map[string]string{"lib/lib.go": "package lib\n\nfunc Expected() {}\n"}).
+				AddLightweightTag("1.0.0").
+				AddCommitWithContent("fix: bug", map[string]string{"lib/lib.go": "package lib\n"})
+
+			err := runWithArgs(bed.Path(), "--explain", "--go-api", "--allow-major")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("2.0.0\n"))
+			Expect(rec.Stderr.String()).To(ContainSubstring("major go-api: lib: removed func Expected"))
+		})
+	})
+
+	Describe("--verbose and --log-format", func() {
+		BeforeEach(func() {
+			bed.
+				AddCommits("initial").
+				AddLightweightTag("v1.0.0").
+				AddLightweightTag("not-a-version").
+				AddCommits("feat: expected feature")
+		})
+
+		It("logs nothing by default", func() {
+			Expect(runWithArgs(bed.Path(), "-t", "v")).ToNot(HaveOccurred())
+			Expect(rec.Stderr.String()).To(BeEmpty())
+		})
+
+		It("logs the commit range and the next version with -v", func() {
+			Expect(runWithArgs(bed.Path(), "-v", "-t", "v")).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("1.1.0\n"))
+			Expect(rec.Stderr.String()).To(ContainSubstring(`level=info msg="commit range" from=` + bed.Commits()[0].Hash.String() + " until=v1.0.0"))
+			Expect(rec.Stderr.String()).To(ContainSubstring(`level=info msg="next version" version=1.1.0 bump=minor`))
+			Expect(rec.Stderr.String()).ToNot(ContainSubstring("level=debug"))
+		})
+
+		It("logs the tags and the keyword matches with -vv", func() {
+			Expect(runWithArgs(bed.Path(), "-vv", "-t", "v")).ToNot(HaveOccurred())
+			Expect(rec.Stderr.String()).To(ContainSubstring(`level=debug msg="tag skipped" tag=not-a-version reason="no tag prefix v"`))
+			Expect(rec.Stderr.String()).To(ContainSubstring(`level=debug msg="keyword matched" commit="feat: expected feature" bump=minor keyword=^feat:`))
+		})
+
+		It("logs JSON objects", func() {
+			Expect(runWithArgs(bed.Path(), "-v", "-t", "v", "--log-format", "json")).ToNot(HaveOccurred())
+			lines := strings.Split(strings.TrimSpace(rec.Stderr.String()), "\n")
+			for _, line := range lines {
+				var entry map[string]interface{}
+				Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+				Expect(entry).To(HaveKeyWithValue("level", "info"))
+			}
+			Expect(lines).To(ContainElement(`{"level":"info","msg":"next version","version":"1.1.0","bump":"minor"}`))
+		})
+	})
+
+	Describe("--changelog", func() {
+		It("writes the commits and the detected changes as markdown into the given file", func() {
+			filename := path.Join(emptyTempDir, "CHANGELOG.md")
+			bed.
+				AddCommitWithContent("initial", map[string]string{"openapi.yaml": "openapi: 3.0.0\npaths:\n  /pets:\n    get: {}\n"}).
+				AddLightweightTag("1.0.0").
+				AddCommitWithContent("fix: expected fix", map[string]string{"openapi.yaml": "openapi: 3.0.0\npaths: {}\n"})
+
+			err := runWithArgs(bed.Path(), "--changelog", filename, "--openapi", "openapi.yaml", "--explain", "--allow-major")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("2.0.0\n"))
+			expectFileToContain(filename,
+				"## 2.0.0",
+				"### Breaking changes\n\n- openapi: openapi.yaml: removed endpoint GET /pets\n",
+				"### Fixes\n\n- fix: expected fix (",
+			)
+			Expect(rec.Stderr.String()).To(ContainSubstring("major openapi: openapi.yaml: removed endpoint GET /pets"))
+		})
+	})
+
+	Describe("--validate-config", func() {
+		It("reports that the configuration is valid", func() {
+			err := runWithArgs(bed.Path(), "--validate-config")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(ContainSubstring("valid"))
+		})
+
+		It("reports all problems of the command line and the config file", func() {
+			filename := path.Join(bed.Path(), ".semver-bumper.conf.yaml")
+			writeToFile(&filename, []byte("keywords_major: [\"(\"]\ntag_prefix: \"v \"\n"))()
+
+			err := runWithArgs(bed.Path(), "--validate-config", "-2", "[", "-0", "bad-semver")
+
 			Expect(err).To(HaveOccurred())
+			stderr := rec.Stderr.String()
+			Expect(stderr).To(ContainSubstring("command line: keywords_minor[0]: invalid regular expression"))
+			Expect(stderr).To(ContainSubstring("command line: initial_version: invalid initial version bad-semver"))
+			Expect(stderr).To(ContainSubstring(filename + ": keywords_major[0]: invalid regular expression"))
+			Expect(stderr).To(ContainSubstring(filename + ": tag_prefix: invalid tag prefix"))
+		})
+	})
+
+	Describe("--preset", func() {
+		It("uses the keywords of the preset", func() {
+			bed.
+				AddCommits("initial").
+				AddLightweightTag("1.0.0").
+				AddCommits("✨ feature")
+
+			Expect(runWithArgs(bed.Path(), "--preset", "gitmoji")).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("1.1.0\n"))
+		})
+
+		It("prints the resolved keywords", func() {
+			err := runWithArgs(bed.Path(), "--preset", "emoji-bumper", "--print-keywords", "-1", "keyword-a", "-1", "...")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(ContainSubstring("major:\n\tkeyword-a\n\t^🤑\nminor:\n\t^🎉"))
+		})
+
+		It("extends the preset from the config file", func() {
+			filename := path.Join(bed.Path(), ".semver-bumper.conf.yaml")
+			writeToFile(&filename, []byte("extends: emoji-bumper\nkeywords_minor: [\"^expected\", \"...\"]\n"))()
+
+			err := runWithArgs(bed.Path(), "--print-keywords")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(ContainSubstring("minor:\n\t^expected\n\t^🎉"))
+		})
+	})
+
+	Describe("--print-config", func() {
+		var filename string
+		BeforeEach(func() {
+			filename = path.Join(bed.Path(), ".semver-bumper.conf.yaml")
+			writeToFile(&filename, []byte("tag_prefix: v\npath_exclude: [docs]\n"))()
+		})
+
+		It("prints the effective configuration with the source of each value", func() {
+			err := runWithArgs(bed.Path(), "--print-config", "--pre", "rc")
+
+			Expect(err).ToNot(HaveOccurred())
+			stdout := rec.Stdout.String()
+			Expect(stdout).To(ContainSubstring("tag_prefix: v # " + filename + "\n"))
+			Expect(stdout).To(ContainSubstring("pre: rc # command line\n"))
+			Expect(stdout).To(ContainSubstring("initial_version: 1.0.0 # default\n"))
+		})
+
+		It("prints JSON", func() {
+			err := runWithArgs(bed.Path(), "--print-config=json")
+
+			Expect(err).ToNot(HaveOccurred())
+			var actual map[string]map[string]interface{}
+			Expect(json.Unmarshal(rec.Stdout.Bytes(), &actual)).To(Succeed())
+			Expect(actual["config"]).To(HaveKeyWithValue("tag_prefix", "v"))
+			Expect(actual["sources"]).To(HaveKeyWithValue("tag_prefix", filename))
+		})
+
+		It("prints only the keys of the config file", func() {
+			err := runWithArgs(bed.Path(), "--print-config", "--pre", "rc", "--fake-prerelease", "1.2.3-rc.1")
+			Expect(err).ToNot(HaveOccurred())
+			var yamlConfig map[string]interface{}
+			Expect(yaml.Unmarshal(rec.Stdout.Bytes(), &yamlConfig)).To(Succeed())
+
+			err = runWithArgs(bed.Path(), "--print-config=json", "--pre", "rc", "--fake-prerelease", "1.2.3-rc.1")
+			Expect(err).ToNot(HaveOccurred())
+			var jsonConfig map[string]map[string]interface{}
+			Expect(json.Unmarshal(rec.Stdout.Bytes(), &jsonConfig)).To(Succeed())
+
+			keys := configFileKeys()
+			for key := range yamlConfig {
+				Expect(keys).To(ContainElement(key))
+			}
+			for key := range jsonConfig["config"] {
+				Expect(keys).To(ContainElement(key))
+			}
+		})
+
+		It("shows a list cleared by an empty flag", func() {
+			err := runWithArgs(bed.Path(), "--print-config", "--path-exclude=")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).ToNot(ContainSubstring("docs"))
+		})
+	})
+
+	Describe("values depending on a command line argument", func() {
+		It("accepts a prerelease release-as version of the config file with --pre", func() {
+			filename := path.Join(bed.Path(), ".semver-bumper.conf.yaml")
+			writeToFile(&filename, []byte("release_as: 2.0.0-rc.1\n"))()
+
+			err := runWithArgs(bed.Path(), "--pre", "rc")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("2.0.0-rc.1\n"))
+		})
+
+		It("accepts a calendar initial version of the config file with --scheme", func() {
+			filename := path.Join(bed.Path(), ".semver-bumper.conf.yaml")
+			writeToFile(&filename, []byte("initial_version: 26.01.0\n"))()
+
+			err := runWithArgs(bed.Path(), "--scheme", "YY.0M.MICRO")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("26.01.0\n"))
+		})
+
+		It("reports the source of the value", func() {
+			filename := path.Join(bed.Path(), ".semver-bumper.conf.yaml")
+			writeToFile(&filename, []byte("release_as: 2.0.0-rc.1\n"))()
+
+			err := runWithArgs(bed.Path())
 
-			stderr := err.Error()
-			Expect(stderr).To(ContainSubstring("keywords"))
-			Expect(stderr).To(ContainSubstring("major"))
-			Expect(stderr).To(ContainSubstring("minor"))
-			Expect(stderr).To(ContainSubstring("patch"))
-			Expect(stderr).To(ContainSubstring("keyword-a"))
-			Expect(stderr).To(ContainSubstring("keyword-b"))
-			Expect(stderr).To(ContainSubstring("keyword-c"))
+			Expect(err).To(MatchError(ContainSubstring(filename + ": release_as: release-as version 2.0.0-rc.1 is a prerelease but no prerelease keyword is given")))
+		})
+	})
+
+	Describe("environment variables", func() {
+		It("reads options from SEMVER_BUMPER_* variables", func() {
+			err := runWithEnv([]string{"SEMVER_BUMPER_INITIAL_VERSION=3.14.159"}, bed.Path())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("3.14.159\n"))
+		})
+
+		It("has a lower precedence than the command line", func() {
+			err := runWithEnv([]string{"SEMVER_BUMPER_INITIAL_VERSION=1.2.3"}, bed.Path(), "-0", "3.14.159")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("3.14.159\n"))
+		})
+
+		It("accepts a value which is only valid with a command line argument", func() {
+			err := runWithEnv([]string{"SEMVER_BUMPER_RELEASE_AS=2.0.0-rc.1"}, bed.Path(), "--pre", "rc")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("2.0.0-rc.1\n"))
+		})
+
+		It("has a higher precedence than the config file", func() {
+			filename := path.Join(bed.Path(), ".semver-bumper.conf.yaml")
+			writeToFile(&filename, []byte("initial_version: 1.2.3\n"))()
+
+			err := runWithEnv([]string{"SEMVER_BUMPER_INITIAL_VERSION=3.14.159"}, bed.Path())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("3.14.159\n"))
+		})
+
+		It("selects the config file", func() {
+			filename := path.Join(emptyTempDir, "config.yaml")
+			writeToFile(&filename, []byte("initial_version: 3.14.159\n"))()
+
+			err := runWithEnv([]string{"SEMVER_BUMPER_CONFIG_FILE=" + filename}, bed.Path())
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("3.14.159\n"))
+		})
+
+		It("reports invalid values with the environment as source", func() {
+			err := runWithEnv([]string{"SEMVER_BUMPER_INITIAL_VERSION=bad-semver"}, bed.Path())
+
+			Expect(err).To(HaveOccurred())
+			Expect(rec.Stderr.String()).To(ContainSubstring("environment: initial_version: invalid initial version bad-semver"))
+		})
+
+		It("is included in --validate-config", func() {
+			err := runWithEnv([]string{"SEMVER_BUMPER_MAJOR=(", "SEMVER_BUMPER_VALIDATE_CONFIG=true"}, bed.Path(), "-0", "bad-semver")
+
+			Expect(err).To(HaveOccurred())
+			stderr := rec.Stderr.String()
+			Expect(stderr).To(ContainSubstring("command line: initial_version: invalid initial version bad-semver"))
+			Expect(stderr).To(ContainSubstring("environment: keywords_major[0]: invalid regular expression"))
 		})
 	})
 
@@ -90,6 +386,116 @@ var _ = Describe("Run", func() {
 		})
 	})
 
+	Describe("--constraint and --allow-major", func() {
+		BeforeEach(func() {
+			bed.
+				AddCommits("initial").
+				AddLightweightTag("2.4.0").
+				AddCommits("BREAKING CHANGE: expected breaking change")
+		})
+
+		It("fails with the commits justifying a major bump without --allow-major", func() {
+			Expect(runWithArgs(bed.Path())).To(MatchError(ContainSubstring("major version bump is not allowed")))
+			Expect(rec.Stdout.String()).To(BeEmpty())
+			Expect(rec.Stderr.String()).To(ContainSubstring("BREAKING CHANGE: expected breaking change"))
+		})
+
+		It("releases the major version with --allow-major", func() {
+			Expect(runWithArgs(bed.Path(), "--allow-major")).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("3.0.0\n"))
+		})
+
+		It("fails if the version does not match the constraint before tagging", func() {
+			Expect(runWithArgs("tag", "--allow-major", "--constraint", "<3.0.0", bed.Path())).To(MatchError(ContainSubstring("version 3.0.0 does not match the constraint <3.0.0")))
+
+			Expect(runWithArgs("list", "--filter", ">=3", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(BeEmpty())
+		})
+
+		It("releases the version matching the constraint", func() {
+			Expect(runWithArgs("next", "--allow-major", "--constraint", "<4.0.0", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("3.0.0\n"))
+		})
+	})
+
+	Describe("--scheme", func() {
+		thisMonth := testNow.Format("06.01")
+
+		BeforeEach(func() {
+			bed.
+				AddCommits("initial").
+				AddLightweightTag("v20.01.4").
+				AddCommits("feat: expected feature")
+		})
+
+		It("writes the calendar version in the given format", func() {
+			Expect(runWithArgs(bed.Path(), "-t", "v", "--scheme", "YY.0M.MICRO", "--output-format", "dotenv")).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("VERSION=" + thisMonth + ".0\nPREVIOUS_VERSION=20.01.4\nBUMP_LEVEL=minor\nTAG=v" + thisMonth + ".0\nIS_PRERELEASE=false\n"))
+		})
+
+		It("creates the tag in the given format", func() {
+			Expect(runWithArgs("tag", "-t", "v", "--scheme", "YY.0M.MICRO", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("v" + thisMonth + ".0\n"))
+
+			Expect(runWithArgs("list", "-t", "v", "--scheme", "YY.0M.MICRO", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(ContainSubstring(thisMonth + ".0\tv" + thisMonth + ".0\t"))
+		})
+
+		It("rejects an invalid format", func() {
+			Expect(runWithArgs(bed.Path(), "--scheme", "MM.YYYY")).To(MatchError(ContainSubstring("invalid version scheme")))
+		})
+	})
+
+	Describe("--output-format", func() {
+		BeforeEach(func() {
+			bed.
+				AddCommits("initial").
+				AddLightweightTag("v1.2.3").
+				AddCommits("feat: expected feature")
+		})
+
+		It("writes a dotenv file", func() {
+			Expect(runWithArgs(bed.Path(), "-t", "v", "--output-format", "dotenv")).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("VERSION=1.3.0\nPREVIOUS_VERSION=1.2.3\nBUMP_LEVEL=minor\nTAG=v1.3.0\nIS_PRERELEASE=false\n"))
+		})
+
+		It("writes the bump level of --release-as", func() {
+			Expect(runWithArgs(bed.Path(), "-t", "v", "--output-format", "dotenv", "--release-as", "5.0.0")).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("VERSION=5.0.0\nPREVIOUS_VERSION=1.2.3\nBUMP_LEVEL=major\nTAG=v5.0.0\nIS_PRERELEASE=false\n"))
+		})
+
+		It("writes a GitLab dotenv report", func() {
+			Expect(runWithArgs(bed.Path(), "-t", "v", "--output-format", "gitlab", "--pre", "rc")).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("VERSION=1.3.0-rc.1\nPREVIOUS_VERSION=1.2.3\nBUMP_LEVEL=minor\nTAG=v1.3.0-rc.1\nIS_PRERELEASE=true\n"))
+		})
+
+		It("writes export lines", func() {
+			Expect(runWithArgs(bed.Path(), "-t", "v", "--output-format", "export")).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(HavePrefix("export VERSION='1.3.0'\nexport PREVIOUS_VERSION='1.2.3'\n"))
+		})
+
+		It("appends GitHub Actions outputs to $GITHUB_OUTPUT", func() {
+			filename := path.Join(emptyTempDir, "github-output")
+			writeToFile(&filename, []byte("existing=value\n"))()
+
+			err := runWithEnv([]string{"GITHUB_OUTPUT=" + filename}, bed.Path(), "-t", "v", "--output-format", "github")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(BeEmpty())
+			content := fileContent(filename)
+			Expect(content).To(HavePrefix("existing=value\nVERSION<<SEMVER_BUMPER_EOF\n1.3.0\nSEMVER_BUMPER_EOF\n"))
+			Expect(content).To(ContainSubstring("IS_PRERELEASE<<SEMVER_BUMPER_EOF\nfalse\nSEMVER_BUMPER_EOF\n"))
+		})
+
+		It("rejects an invalid format in the config file", func() {
+			filename := path.Join(bed.Path(), ".semver-bumper.conf.yaml")
+			writeToFile(&filename, []byte("output_format: xml\n"))()
+
+			Expect(runWithArgs(bed.Path())).To(HaveOccurred())
+			Expect(rec.Stderr.String()).To(ContainSubstring("output_format: invalid output format xml"))
+		})
+	})
+
 	Describe("--commits", func() {
 		var filename string
 		BeforeEach(func() {
@@ -121,7 +527,7 @@ var _ = Describe("Run", func() {
 
 			err := runWithArgs(bed.Path(), "--commits", filename)
 
-			Expect(err).ToNot(HaveOccurred())
+			Expect(err).To(MatchError(bumper.ErrNothingToRelease))
 			expectFileToContain(filename, "expected-1", "expected-2")
 		})
 	})
@@ -182,9 +588,9 @@ var _ = Describe("Run", func() {
 					"--path-exclude", expectedPathExclude1,
 					"--path-exclude", expectedPathExclude2,
 				)
-				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("written"))
-				Expect(err.Error()).To(ContainSubstring(filename))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rec.Stdout.String()).To(ContainSubstring("written"))
+				Expect(rec.Stdout.String()).To(ContainSubstring(filename))
 			}
 			var expectFileToUnmarshalAndEqual = func(unmarshal func([]byte, interface{}) error) {
 				data, err := os.ReadFile(filename)
@@ -194,7 +600,10 @@ var _ = Describe("Run", func() {
 				Expect(unmarshal(data, &actualResult)).ToNot(HaveOccurred())
 				Expect(actualResult.Valid()).ToNot(HaveOccurred())
 
-				Expect(actualResult).To(Equal(expectedResult))
+				// the values are equal, their sources are not
+				expectedJson, err := json.Marshal(expectedResult)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(json.Marshal(actualResult)).To(MatchJSON(expectedJson))
 			}
 			When("the filename ends with .json", func() {
 				BeforeEach(func() {
@@ -288,10 +697,13 @@ var _ = Describe("Run", func() {
 						AddCommits("commit-3")
 				})
 				It("runs with the flags read from the config file", func() {
-					Expect(runWithConfigFile()).ToNot(HaveOccurred())
+					Expect(runWithConfigFile()).To(MatchError(bumper.ErrNothingToRelease))
 					Expect(rec.Stdout.String()).To(Equal("1.2.3\n"))
 				})
 				When("there are also command line arguments", func() {
+					BeforeEach(func() {
+						bed.AddCommits("fix: commit-4")
+					})
 					It("uses both flags from the config file and the command line", func() {
 						Expect(runWithConfigFile("--pre", expectedPrereleasePrefix)).ToNot(HaveOccurred())
 						Expect(rec.Stdout.String()).To(Equal("1.2.4-" + expectedPrereleasePrefix + ".11\n"))
@@ -312,6 +724,36 @@ var _ = Describe("Run", func() {
 				})
 			})
 		})
+		Describe("user config file", func() {
+			var environ []string
+			BeforeEach(func() {
+				environ = []string{"XDG_CONFIG_HOME=" + emptyTempDir}
+				Expect(os.Mkdir(path.Join(emptyTempDir, "semver-bumper"), 0755)).To(Succeed())
+				writeAConfigFile(
+					path.Join(emptyTempDir, "semver-bumper", "config.yaml"),
+					yamlEncoder,
+					&Options{InitialVersion: "9.8.7", TagPrefix: "v"},
+				)
+			})
+			It("runs with the flags from that config file", func() {
+				Expect(runWithEnv(environ, bed.Path())).ToNot(HaveOccurred())
+				Expect(rec.Stdout.String()).To(Equal("9.8.7\n"))
+			})
+			It("has a lower precedence than the project config file", func() {
+				bed.
+					AddCommits("initial").
+					AddLightweightTag("v1.2.3").
+					AddLightweightTag("r3.2.1")
+				writeAConfigFile(
+					path.Join(bed.Path(), ".semver-bumper.conf"),
+					yamlEncoder,
+					&Options{InitialVersion: "1.0.0", NoMatchBump: "patch"},
+				)
+
+				Expect(runWithEnv(environ, bed.Path())).ToNot(HaveOccurred())
+				Expect(rec.Stdout.String()).To(Equal("1.2.4\n"))
+			})
+		})
 		Describe("project config file", func() {
 			Describe("filename and content", func() {
 				DescribeTable(
@@ -367,6 +809,22 @@ var _ = Describe("Run", func() {
 					Expect(runWithArgs(bed.Path())).ToNot(HaveOccurred())
 					Expect(rec.Stdout.String()).To(Equal(projectConfig.InitialVersion + "\n"))
 				})
+				When("the tool runs in a subdirectory with its own project config file", func() {
+					var subdir string
+					BeforeEach(func() {
+						bed.
+							AddCommitAt("services/api/one", "one").
+							AddLightweightTag("987.65.4").
+							AddCommitAt("services/api/two", "fix: two").
+							AddCommitAt("services/web/three", "feat: three")
+						subdir = path.Join(bed.Path(), "services", "api")
+						writeAConfigFile(path.Join(subdir, ".semver-bumper.conf"), yamlEncoder, &Options{ScopeToDir: true})
+					})
+					It("runs with the flags from the nearest config file and the commits changing the subdirectory", func() {
+						Expect(runWithArgs(subdir)).ToNot(HaveOccurred())
+						Expect(rec.Stdout.String()).To(Equal("987.65.5\n"))
+					})
+				})
 				When("there also is a --config-file command line argument", func() {
 					BeforeEach(writeANewConfigFile(yamlEncoder, &Options{TagPrefix: "v"}))
 					It("ignores the flags from that config file", func() {
@@ -447,6 +905,20 @@ var yamlEncoder encoderFn = func(file *os.File) encoder {
 	return yaml.NewEncoder(file)
 }
 
+// configFileKeys are the keys of the options in a config file
+func configFileKeys() []string {
+	var keys []string
+	t := reflect.TypeOf(Options{})
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if key != "" && key != "-" {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
 func writeToFile(filename *string, data []byte) func() {
 	return func() {
 		Expect(os.WriteFile(*filename, data, 0644)).ToNot(HaveOccurred())
@@ -4,6 +4,7 @@ package clifakes
 import (
 	"io"
 	"sync"
+	"time"
 
 	"github.com/timotto/semver-bumper/internal/cli"
 )
@@ -19,6 +20,26 @@ type FakeOs struct {
 	argsReturnsOnCall map[int]struct {
 		result1 []string
 	}
+	EnvironStub        func() []string
+	environMutex       sync.RWMutex
+	environArgsForCall []struct {
+	}
+	environReturns struct {
+		result1 []string
+	}
+	environReturnsOnCall map[int]struct {
+		result1 []string
+	}
+	NowStub        func() time.Time
+	nowMutex       sync.RWMutex
+	nowArgsForCall []struct {
+	}
+	nowReturns struct {
+		result1 time.Time
+	}
+	nowReturnsOnCall map[int]struct {
+		result1 time.Time
+	}
 	StderrStub        func() io.Writer
 	stderrMutex       sync.RWMutex
 	stderrArgsForCall []struct {
@@ -29,6 +50,16 @@ type FakeOs struct {
 	stderrReturnsOnCall map[int]struct {
 		result1 io.Writer
 	}
+	StdinStub        func() io.Reader
+	stdinMutex       sync.RWMutex
+	stdinArgsForCall []struct {
+	}
+	stdinReturns struct {
+		result1 io.Reader
+	}
+	stdinReturnsOnCall map[int]struct {
+		result1 io.Reader
+	}
 	StdoutStub        func() io.Writer
 	stdoutMutex       sync.RWMutex
 	stdoutArgsForCall []struct {
@@ -96,6 +127,114 @@ func (fake *FakeOs) ArgsReturnsOnCall(i int, result1 []string) {
 	}{result1}
 }
 
+func (fake *FakeOs) Environ() []string {
+	fake.environMutex.Lock()
+	ret, specificReturn := fake.environReturnsOnCall[len(fake.environArgsForCall)]
+	fake.environArgsForCall = append(fake.environArgsForCall, struct {
+	}{})
+	stub := fake.EnvironStub
+	fakeReturns := fake.environReturns
+	fake.recordInvocation("Environ", []interface{}{})
+	fake.environMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeOs) EnvironCallCount() int {
+	fake.environMutex.RLock()
+	defer fake.environMutex.RUnlock()
+	fake.nowMutex.RLock()
+	defer fake.nowMutex.RUnlock()
+	return len(fake.environArgsForCall)
+}
+
+func (fake *FakeOs) EnvironCalls(stub func() []string) {
+	fake.environMutex.Lock()
+	defer fake.environMutex.Unlock()
+	fake.EnvironStub = stub
+}
+
+func (fake *FakeOs) EnvironReturns(result1 []string) {
+	fake.environMutex.Lock()
+	defer fake.environMutex.Unlock()
+	fake.EnvironStub = nil
+	fake.environReturns = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeOs) EnvironReturnsOnCall(i int, result1 []string) {
+	fake.environMutex.Lock()
+	defer fake.environMutex.Unlock()
+	fake.EnvironStub = nil
+	if fake.environReturnsOnCall == nil {
+		fake.environReturnsOnCall = make(map[int]struct {
+			result1 []string
+		})
+	}
+	fake.environReturnsOnCall[i] = struct {
+		result1 []string
+	}{result1}
+}
+
+func (fake *FakeOs) Now() time.Time {
+	fake.nowMutex.Lock()
+	ret, specificReturn := fake.nowReturnsOnCall[len(fake.nowArgsForCall)]
+	fake.nowArgsForCall = append(fake.nowArgsForCall, struct {
+	}{})
+	stub := fake.NowStub
+	fakeReturns := fake.nowReturns
+	fake.recordInvocation("Now", []interface{}{})
+	fake.nowMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeOs) NowCallCount() int {
+	fake.nowMutex.RLock()
+	defer fake.nowMutex.RUnlock()
+	return len(fake.nowArgsForCall)
+}
+
+func (fake *FakeOs) NowCalls(stub func() time.Time) {
+	fake.nowMutex.Lock()
+	defer fake.nowMutex.Unlock()
+	fake.NowStub = stub
+}
+
+func (fake *FakeOs) NowReturns(result1 time.Time) {
+	fake.nowMutex.Lock()
+	defer fake.nowMutex.Unlock()
+	fake.NowStub = nil
+	fake.nowReturns = struct {
+		result1 time.Time
+	}{result1}
+}
+
+func (fake *FakeOs) NowReturnsOnCall(i int, result1 time.Time) {
+	fake.nowMutex.Lock()
+	defer fake.nowMutex.Unlock()
+	fake.NowStub = nil
+	if fake.nowReturnsOnCall == nil {
+		fake.nowReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+		})
+	}
+	fake.nowReturnsOnCall[i] = struct {
+		result1 time.Time
+	}{result1}
+}
+
 func (fake *FakeOs) Stderr() io.Writer {
 	fake.stderrMutex.Lock()
 	ret, specificReturn := fake.stderrReturnsOnCall[len(fake.stderrArgsForCall)]
@@ -149,6 +288,59 @@ func (fake *FakeOs) StderrReturnsOnCall(i int, result1 io.Writer) {
 	}{result1}
 }
 
+func (fake *FakeOs) Stdin() io.Reader {
+	fake.stdinMutex.Lock()
+	ret, specificReturn := fake.stdinReturnsOnCall[len(fake.stdinArgsForCall)]
+	fake.stdinArgsForCall = append(fake.stdinArgsForCall, struct {
+	}{})
+	stub := fake.StdinStub
+	fakeReturns := fake.stdinReturns
+	fake.recordInvocation("Stdin", []interface{}{})
+	fake.stdinMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeOs) StdinCallCount() int {
+	fake.stdinMutex.RLock()
+	defer fake.stdinMutex.RUnlock()
+	return len(fake.stdinArgsForCall)
+}
+
+func (fake *FakeOs) StdinCalls(stub func() io.Reader) {
+	fake.stdinMutex.Lock()
+	defer fake.stdinMutex.Unlock()
+	fake.StdinStub = stub
+}
+
+func (fake *FakeOs) StdinReturns(result1 io.Reader) {
+	fake.stdinMutex.Lock()
+	defer fake.stdinMutex.Unlock()
+	fake.StdinStub = nil
+	fake.stdinReturns = struct {
+		result1 io.Reader
+	}{result1}
+}
+
+func (fake *FakeOs) StdinReturnsOnCall(i int, result1 io.Reader) {
+	fake.stdinMutex.Lock()
+	defer fake.stdinMutex.Unlock()
+	fake.StdinStub = nil
+	if fake.stdinReturnsOnCall == nil {
+		fake.stdinReturnsOnCall = make(map[int]struct {
+			result1 io.Reader
+		})
+	}
+	fake.stdinReturnsOnCall[i] = struct {
+		result1 io.Reader
+	}{result1}
+}
+
 func (fake *FakeOs) Stdout() io.Writer {
 	fake.stdoutMutex.Lock()
 	ret, specificReturn := fake.stdoutReturnsOnCall[len(fake.stdoutArgsForCall)]
@@ -207,8 +399,14 @@ func (fake *FakeOs) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.argsMutex.RLock()
 	defer fake.argsMutex.RUnlock()
+	fake.environMutex.RLock()
+	defer fake.environMutex.RUnlock()
+	fake.nowMutex.RLock()
+	defer fake.nowMutex.RUnlock()
 	fake.stderrMutex.RLock()
 	defer fake.stderrMutex.RUnlock()
+	fake.stdinMutex.RLock()
+	defer fake.stdinMutex.RUnlock()
 	fake.stdoutMutex.RLock()
 	defer fake.stdoutMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
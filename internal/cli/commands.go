@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jessevdk/go-flags"
+	"github.com/timotto/semver-bumper/pkg/bumper"
+	"github.com/timotto/semver-bumper/pkg/changelog"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	"os"
+	"path"
+)
+
+const defaultConfigFilename = ".semver-bumper.conf.yaml"
+
+type (
+	// app is shared by all commands, the options are parsed before any command is executed
+	app struct {
+		os   Os
+		opts *Options
+	}
+
+	nextCommand struct {
+		app *app
+	}
+
+	currentCommand struct {
+		app    *app
+		Format string `short:"f" long:"format" choice:"text" choice:"json" default:"text" description:"output format"`
+	}
+
+	listCommand struct {
+		app    *app
+		Filter string `long:"filter" description:"only list versions matching the constraint, eg \">= 1.2, < 2\""`
+		Format string `short:"f" long:"format" choice:"text" choice:"json" default:"text" description:"output format"`
+	}
+
+	logCommand struct {
+		app *app
+	}
+
+	tagCommand struct {
+		app    *app
+		DryRun bool `long:"dry-run" description:"print the tag without creating it"`
+	}
+
+	changelogCommand struct {
+		app *app
+	}
+
+	replayCommand struct {
+		app    *app
+		From   string   `long:"from" description:"replay the history after the given commit instead of from the root commit"`
+		Every  string   `long:"every" choice:"commit" choice:"merge" default:"commit" description:"simulate a release at every commit or at every merge commit of the first parent chain"`
+		Dates  []string `long:"date" description:"simulate a release at the last commit of each date, eg 2021-06-30, replaces --every, can be supplied multiple times"`
+		Apply  bool     `long:"apply" description:"create the tags of the plan"`
+		Format string   `short:"f" long:"format" choice:"text" choice:"json" default:"text" description:"output format"`
+	}
+
+	configCommand struct{}
+
+	configPrintCommand struct {
+		app    *app
+		Format string `short:"f" long:"format" choice:"yaml" choice:"json" default:"yaml" description:"output format"`
+	}
+
+	configValidateCommand struct {
+		app *app
+	}
+
+	configInitCommand struct {
+		app   *app
+		Force bool `long:"force" description:"overwrite an existing config file"`
+	}
+
+	lintCommand struct {
+		app   *app
+		File  string `long:"file" description:"lint the commit message in the file, \"-\" for stdin, eg the argument of a commit-msg hook"`
+		Range string `long:"range" description:"lint the commits in the range, eg \"v1.2.3..HEAD\", defaults to the commits since the latest release"`
+	}
+
+	hooksCommand struct{}
+
+	hooksInstallCommand struct {
+		app   *app
+		Force bool `long:"force" description:"overwrite an existing hook"`
+	}
+)
+
+func newParser(a *app) *flags.Parser {
+	parser := NewParser(a.opts)
+	parser.SubcommandsOptional = true
+	parser.CommandHandler = func(cmd flags.Commander, args []string) error {
+		a.opts.Parsed()
+		if cmd == nil {
+			cmd = &nextCommand{app: a}
+		}
+
+		return cmd.Execute(args)
+	}
+
+	mustAddCommand(parser.AddCommand("next", "print the next version", "Estimate the next version from the commits since the latest release, the default command.", &nextCommand{app: a}))
+	mustAddCommand(parser.AddCommand("current", "print the latest release and prerelease", "Print the latest release and the latest prerelease after it with their tag and commit.", &currentCommand{app: a}))
+	mustAddCommand(parser.AddCommand("list", "print all versions", "Print all releases and prereleases with their tag, commit, and date, the latest one last.", &listCommand{app: a}))
+	mustAddCommand(parser.AddCommand("log", "print the commits of the next version", "Print the commits relevant to the next version like \"git log --format=oneline\".", &logCommand{app: a}))
+	mustAddCommand(parser.AddCommand("tag", "tag HEAD with the next version", "Create a lightweight git tag with the tag prefix and the next version at HEAD.", &tagCommand{app: a}))
+	mustAddCommand(parser.AddCommand("changelog", "print the changelog of the next version", "Print the markdown changelog of the next version.", &changelogCommand{app: a}))
+	mustAddCommand(parser.AddCommand("replay", "plan the tags of an untagged history", "Walk the history from the root commit and print the tags of the releases which would have occurred at every commit, at every merge, or at the given dates. Existing release tags are kept and continue the replay.", &replayCommand{app: a}))
+	mustAddCommand(parser.AddCommand("lint", "check commit messages", "Print the bump level of a commit message, of the commits in a range, or of the commits since the latest release.", &lintCommand{app: a}))
+
+	config, err := parser.AddCommand("config", "manage the configuration", "Print, validate, or create the configuration.", &configCommand{})
+	mustAddCommand(config, err)
+	mustAddCommand(config.AddCommand("print", "print the effective configuration", "Print the effective configuration with the source of each value.", &configPrintCommand{app: a}))
+	mustAddCommand(config.AddCommand("validate", "report all problems of the configuration", "Report all problems of the command line, the environment, and the config files.", &configValidateCommand{app: a}))
+	mustAddCommand(config.AddCommand("init", "create a project config file", "Write the given parameters into "+defaultConfigFilename+" in the git repository, or into the --write-config file.", &configInitCommand{app: a}))
+
+	hooks, err := parser.AddCommand("hooks", "manage git hooks", "Install git hooks calling semver-bumper.", &hooksCommand{})
+	mustAddCommand(hooks, err)
+	mustAddCommand(hooks.AddCommand("install", "install the commit-msg hook", "Write a commit-msg hook into the hooks directory of the git repository, which rejects commit messages matching no keyword.", &hooksInstallCommand{app: a}))
+
+	return parser
+}
+
+func mustAddCommand(_ *flags.Command, err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// runtime reads the options and opens the git repository given in the remaining arguments
+func (a *app) runtime(args []string) (*runtime, error) {
+	gitRepoPath, err := GitRepoPath(args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := readOptions(a.os, a.opts, gitRepoPath); err != nil {
+		return nil, err
+	}
+
+	return newRuntime(a.os, a.opts, gitRepoPath)
+}
+
+func (c *nextCommand) Execute(args []string) error {
+	gitRepoPath, err := GitRepoPath(args)
+	if err != nil {
+		return err
+	}
+
+	if c.app.opts.ValidateConfig || validateConfigFromEnv(c.app.os) {
+		return validateConfig(c.app.os, c.app.opts, gitRepoPath)
+	}
+
+	if err := readOptions(c.app.os, c.app.opts, gitRepoPath); err != nil {
+		return err
+	}
+
+	if c.app.opts.ShouldPrintConfig() {
+		return printConfig(c.app.os, c.app.opts, c.app.opts.PrintConfig)
+	}
+
+	rt, err := newRuntime(c.app.os, c.app.opts, gitRepoPath)
+	if err != nil {
+		return err
+	}
+
+	return rt.run()
+}
+
+func (c *logCommand) Execute(args []string) error {
+	rt, err := c.app.runtime(args)
+	if err != nil {
+		return err
+	}
+
+	result, err := rt.bump()
+	if err != nil && !errors.Is(err, bumper.ErrNothingToRelease) {
+		return err
+	}
+
+	must(fmt.Fprint(rt.os.Stdout(), format(result.Commits)))
+
+	return nil
+}
+
+func (c *tagCommand) Execute(args []string) error {
+	rt, err := c.app.runtime(args)
+	if err != nil {
+		return err
+	}
+
+	result, err := rt.bump()
+	if err != nil {
+		return err
+	}
+
+	name := rt.opts.TagPrefix + rt.opts.SchemeValue().String(result.Version)
+	if !c.DryRun {
+		if name, err = rt.repo.CreateTag(result.Version); err != nil {
+			return err
+		}
+	}
+
+	Outln(rt.os, name)
+
+	return nil
+}
+
+func (c *changelogCommand) Execute(args []string) error {
+	rt, err := c.app.runtime(args)
+	if err != nil {
+		return err
+	}
+
+	result, err := rt.bump()
+	if err != nil {
+		return err
+	}
+
+	must(fmt.Fprint(rt.os.Stdout(), changelog.Markdown(rt.opts.SchemeValue().String(result.Version), changelogEntries(result))))
+
+	return nil
+}
+
+func (c *configPrintCommand) Execute(args []string) error {
+	gitRepoPath, err := GitRepoPath(args)
+	if err != nil {
+		return err
+	}
+
+	if err := readOptions(c.app.os, c.app.opts, gitRepoPath); err != nil {
+		return err
+	}
+
+	return printConfig(c.app.os, c.app.opts, c.Format)
+}
+
+func (c *configValidateCommand) Execute(args []string) error {
+	gitRepoPath, err := GitRepoPath(args)
+	if err != nil {
+		return err
+	}
+
+	return validateConfig(c.app.os, c.app.opts, gitRepoPath)
+}
+
+// Execute writes the command line parameters only, the environment and other config files are ignored
+func (c *configInitCommand) Execute(args []string) error {
+	gitRepoPath, err := GitRepoPath(args)
+	if err != nil {
+		return err
+	}
+
+	filename := c.app.opts.WriteConfig
+	if filename == "" {
+		filename = path.Join(gitRepoPath, defaultConfigFilename)
+	}
+
+	if _, err := os.Stat(filename); err == nil && !c.Force {
+		return fmt.Errorf("config file %v exists, use --force to overwrite it", filename)
+	}
+
+	if err := c.app.opts.Valid(); err != nil {
+		return err
+	}
+
+	if err := c.app.opts.WriteToFile(filename); err != nil {
+		return err
+	}
+
+	Outln(c.app.os, "configuration written to", filename)
+
+	return nil
+}
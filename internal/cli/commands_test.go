@@ -0,0 +1,403 @@
+package cli_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jessevdk/go-flags"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/internal/cli"
+	. "github.com/timotto/semver-bumper/internal/cli/clifakes"
+	"github.com/timotto/semver-bumper/pkg/bumper"
+	. "github.com/timotto/semver-bumper/pkg/test/cli_testbed"
+	. "github.com/timotto/semver-bumper/pkg/test/git_testbed"
+	"os"
+	"path"
+	"strings"
+)
+
+var _ = Describe("Commands", func() {
+	var (
+		bed    *TestbedRepo
+		fakeOs *FakeOs
+		rec    *outputRecorder
+	)
+	BeforeEach(CreateBeforeEach(os.TempDir(), &bed))
+	AfterEach(TeardownAfterEach(&bed))
+	BeforeEach(func() {
+		bed.
+			AddCommits("initial").
+			AddLightweightTag("v1.2.3").
+			AddCommits("feat: expected feature", "fix: expected fix")
+	})
+
+	var runWithArgs = func(args ...string) error {
+		fakeOs, rec = newRecordingFakeOs(args...)
+		return Run(fakeOs)
+	}
+
+	DescribeTable(
+		"next is the default command",
+		func(args ...string) {
+			Expect(runWithArgs(append(args, bed.Path())...)).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("1.3.0\n"))
+		},
+		Entry("without a command", "-t", "v"),
+		Entry("with next", "next", "-t", "v"),
+		Entry("with options before next", "-t", "v", "next"),
+	)
+
+	Describe("next", func() {
+		BeforeEach(func() {
+			bed.
+				AddLightweightTag("v1.3.0").
+				AddCommits("unexpected")
+		})
+
+		It("prints the latest release and exits with ExitCodeNothingToRelease if nothing justifies a release", func() {
+			err := runWithArgs("-t", "v", bed.Path())
+
+			Expect(ExitCode(err)).To(Equal(ExitCodeNothingToRelease))
+			Expect(rec.Stdout.String()).To(Equal("1.3.0\n"))
+		})
+
+		It("fails listing the commits matching no keyword with --fail-no-match", func() {
+			err := runWithArgs("--fail-no-match", "-t", "v", bed.Path())
+
+			Expect(ExitCode(err)).To(Equal(ExitCodeError))
+			Expect(rec.Stderr.String()).To(MatchRegexp(`1 commits match no keyword:\n\t[0-9a-f]{7} unexpected\n`))
+			Expect(rec.Stdout.String()).To(BeEmpty())
+		})
+	})
+
+	Describe("current", func() {
+		It("prints the latest release with tag and commit", func() {
+			Expect(runWithArgs("current", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(MatchRegexp("^release\t1.2.3\tv1.2.3\t[0-9a-f]{7}\t[0-9TZ:-]+\n$"))
+		})
+
+		It("prints the latest prerelease", func() {
+			bed.AddLightweightTag("v1.3.0-rc.1")
+
+			Expect(runWithArgs("current", "-t", "v", "-f", "json", bed.Path())).ToNot(HaveOccurred())
+			var actual map[string]map[string]string
+			Expect(json.Unmarshal(rec.Stdout.Bytes(), &actual)).To(Succeed())
+			Expect(actual["release"]).To(HaveKeyWithValue("tag", "v1.2.3"))
+			Expect(actual["prerelease"]).To(HaveKeyWithValue("version", "1.3.0-rc.1"))
+			Expect(actual["prerelease"]).To(HaveKeyWithValue("commit", bed.Commits()[0].Hash.String()))
+		})
+
+		It("does not print a prerelease older than the latest release", func() {
+			bed.
+				AddLightweightTag("v1.3.0-rc.2").
+				AddCommits("release").
+				AddLightweightTag("v1.3.0")
+
+			Expect(runWithArgs("current", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(HavePrefix("release\t1.3.0\t"))
+			Expect(rec.Stdout.String()).ToNot(ContainSubstring("prerelease"))
+		})
+
+		It("fails without a release", func() {
+			Expect(runWithArgs("current", "-t", "r", bed.Path())).To(HaveOccurred())
+		})
+	})
+
+	Describe("list", func() {
+		BeforeEach(func() {
+			bed.
+				AddLightweightTag("v1.3.0-rc.1").
+				AddCommits("more").
+				AddLightweightTag("v2.0.0")
+		})
+
+		It("prints all versions, the latest one last", func() {
+			Expect(runWithArgs("list", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			lines := strings.Split(strings.TrimSpace(rec.Stdout.String()), "\n")
+			Expect(lines).To(HaveLen(3))
+			Expect(lines[0]).To(HavePrefix("1.2.3\tv1.2.3\t"))
+			Expect(lines[1]).To(HavePrefix("1.3.0-rc.1\tv1.3.0-rc.1\t"))
+			Expect(lines[2]).To(HavePrefix("2.0.0\tv2.0.0\t"))
+		})
+
+		It("filters by constraint", func() {
+			Expect(runWithArgs("list", "-t", "v", "--filter", "< 2", "-f", "json", bed.Path())).ToNot(HaveOccurred())
+			var actual []map[string]string
+			Expect(json.Unmarshal(rec.Stdout.Bytes(), &actual)).To(Succeed())
+			Expect(actual).To(HaveLen(1))
+			Expect(actual[0]).To(HaveKeyWithValue("version", "1.2.3"))
+		})
+
+		It("rejects an invalid filter", func() {
+			Expect(runWithArgs("list", "--filter", "not a constraint", bed.Path())).To(HaveOccurred())
+		})
+	})
+
+	Describe("log", func() {
+		It("prints the commits of the next version", func() {
+			Expect(runWithArgs("log", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(ContainSubstring("feat: expected feature\n"))
+			Expect(rec.Stdout.String()).To(ContainSubstring("fix: expected fix\n"))
+			Expect(rec.Stdout.String()).ToNot(ContainSubstring("initial"))
+		})
+	})
+
+	Describe("tag", func() {
+		It("tags HEAD with the next version", func() {
+			Expect(runWithArgs("tag", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("v1.3.0\n"))
+
+			Expect(runWithArgs("current", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(HavePrefix("release\t1.3.0\t"))
+		})
+
+		It("does not create the tag in a dry run", func() {
+			Expect(runWithArgs("tag", "--dry-run", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(Equal("v1.3.0\n"))
+
+			Expect(runWithArgs("current", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(HavePrefix("release\t1.2.3\t"))
+		})
+	})
+
+	Describe("changelog", func() {
+		It("prints the changelog of the next version", func() {
+			Expect(runWithArgs("changelog", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(HavePrefix("## 1.3.0"))
+			Expect(rec.Stdout.String()).To(ContainSubstring("feat: expected feature"))
+		})
+	})
+
+	Describe("replay", func() {
+		It("prints the plan continuing from the existing release", func() {
+			Expect(runWithArgs("replay", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			lines := strings.Split(strings.TrimSpace(rec.Stdout.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(HavePrefix("1.3.0\tv1.3.0\t" + bed.Commits()[1].Hash.String()[:7]))
+			Expect(lines[1]).To(HavePrefix("1.3.1\tv1.3.1\t" + bed.Commits()[0].Hash.String()[:7]))
+		})
+
+		It("releases at every merge", func() {
+			bed.AddMerge("Merge branch", "feat: expected merged feature")
+
+			Expect(runWithArgs("replay", "--every", "merge", "-t", "v", "-f", "json", bed.Path())).ToNot(HaveOccurred())
+			var actual []map[string]string
+			Expect(json.Unmarshal(rec.Stdout.Bytes(), &actual)).To(Succeed())
+			Expect(actual).To(HaveLen(1))
+			Expect(actual[0]).To(HaveKeyWithValue("version", "1.3.0"))
+			Expect(actual[0]).To(HaveKeyWithValue("commit", bed.Commits()[0].Hash.String()))
+		})
+
+		It("releases at the last commit of each date", func() {
+			date := bed.Commits()[0].Committer.When.UTC().Format("2006-01-02")
+
+			Expect(runWithArgs("replay", "--date", date, "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(HavePrefix("1.3.0\tv1.3.0\t" + bed.Commits()[0].Hash.String()[:7]))
+		})
+
+		It("rejects an invalid date", func() {
+			Expect(runWithArgs("replay", "--date", "yesterday", bed.Path())).To(HaveOccurred())
+		})
+
+		It("replays the history after the given commit", func() {
+			Expect(runWithArgs("replay", "--from", "HEAD~1", "-t", "r", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(HavePrefix("1.0.0\tr1.0.0\t" + bed.Commits()[0].Hash.String()[:7]))
+		})
+
+		It("creates the tags with --apply", func() {
+			Expect(runWithArgs("replay", "--apply", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+
+			Expect(runWithArgs("list", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(ContainSubstring("1.3.0\tv1.3.0\t"))
+			Expect(rec.Stdout.String()).To(ContainSubstring("1.3.1\tv1.3.1\t"))
+		})
+
+		When("the history has major bumps", func() {
+			BeforeEach(func() {
+				bed.AddCommits("BREAKING CHANGE: expected break", "BREAKING CHANGE: another break")
+			})
+
+			It("fails without tagging if a major bump is not allowed", func() {
+				err := runWithArgs("replay", "--apply", "-t", "v", bed.Path())
+				Expect(errors.As(err, &bumper.MajorBumpError{})).To(BeTrue())
+				Expect(rec.Stdout.String()).To(BeEmpty())
+
+				Expect(runWithArgs("list", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+				Expect(rec.Stdout.String()).ToNot(ContainSubstring("v1.3.0"))
+			})
+
+			It("fails without tagging if a release does not match the constraint", func() {
+				err := runWithArgs("replay", "--apply", "--allow-major", "--constraint", "<3.0.0", "-t", "v", bed.Path())
+				Expect(err).To(MatchError("version 3.0.0 does not match the constraint <3.0.0"))
+				Expect(rec.Stdout.String()).To(BeEmpty())
+
+				Expect(runWithArgs("list", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+				Expect(rec.Stdout.String()).ToNot(ContainSubstring("v2.0.0"))
+			})
+		})
+	})
+
+	Describe("lint", func() {
+		It("prints the bump level of each commit since the latest release", func() {
+			Expect(runWithArgs("lint", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(MatchRegexp(`minor +[0-9a-f]{7} feat: expected feature\n`))
+			Expect(rec.Stdout.String()).To(MatchRegexp(`patch +[0-9a-f]{7} fix: expected fix\n`))
+		})
+
+		It("fails if a commit matches no keyword with --fail-no-match", func() {
+			bed.AddCommits("unexpected")
+
+			Expect(runWithArgs("lint", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(MatchRegexp(`none +[0-9a-f]{7} unexpected\n`))
+
+			err := runWithArgs("lint", "--fail-no-match", "-t", "v", bed.Path())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("1 of 3 commits match no keyword"))
+		})
+
+		It("lints the commits in a range", func() {
+			Expect(runWithArgs("lint", "--range", "v1.2.3..HEAD~1", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(MatchRegexp(`^minor +[0-9a-f]{7} feat: expected feature\n$`))
+		})
+
+		Describe("--file", func() {
+			var filename string
+			BeforeEach(func() {
+				filename = path.Join(bed.Path(), ".git", "COMMIT_EDITMSG")
+			})
+
+			It("lints the message in the file without git comments", func() {
+				writeToFile(&filename, []byte("# feat: unexpected\nfix: expected\n\n# Please enter the commit message\n"))()
+
+				Expect(runWithArgs("lint", "--file", filename, bed.Path())).ToNot(HaveOccurred())
+				Expect(rec.Stdout.String()).To(Equal("patch fix: expected\n"))
+			})
+
+			It("ignores the diff of a verbose commit", func() {
+				writeToFile(&filename, []byte("unexpected\n# ------------------------ >8 ------------------------\n+feat: unexpected\n"))()
+
+				err := runWithArgs("lint", "--fail-no-match", "--file", filename, bed.Path())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("matches no keyword"))
+				Expect(err.Error()).To(ContainSubstring("^feat:"))
+			})
+
+			It("reads the message from stdin", func() {
+				fakeOs, rec = newRecordingFakeOs("lint", "--file", "-", "--preset", "gitmoji", bed.Path())
+				fakeOs.StdinReturns(strings.NewReader("💥 expected\n"))
+
+				Expect(Run(fakeOs)).ToNot(HaveOccurred())
+				Expect(rec.Stdout.String()).To(Equal("major 💥 expected\n"))
+			})
+		})
+	})
+
+	Describe("hooks install", func() {
+		var filename string
+		BeforeEach(func() {
+			filename = path.Join(bed.Path(), ".git", "hooks", "commit-msg")
+		})
+
+		It("writes an executable commit-msg hook calling lint with the running executable", func() {
+			Expect(runWithArgs("hooks", "install", bed.Path())).ToNot(HaveOccurred())
+
+			executable, err := os.Executable()
+			Expect(err).ToNot(HaveOccurred())
+			expectFileToContain(filename, "exec '"+executable+"' lint --fail-no-match --file \"$1\"")
+			stat, err := os.Stat(filename)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stat.Mode() & 0111).ToNot(BeZero())
+		})
+
+		It("does not overwrite an existing hook without --force", func() {
+			Expect(runWithArgs("hooks", "install", bed.Path())).ToNot(HaveOccurred())
+			Expect(runWithArgs("hooks", "install", bed.Path())).To(HaveOccurred())
+			Expect(runWithArgs("hooks", "install", "--force", bed.Path())).ToNot(HaveOccurred())
+		})
+
+		It("writes the hook of the enclosing repository from a subdirectory", func() {
+			bed.AddCommitAt("services/api/main.go", "one")
+			Expect(runWithArgs("hooks", "install", path.Join(bed.Path(), "services", "api"))).ToNot(HaveOccurred())
+			expectFileToContain(filename, "lint --fail-no-match")
+		})
+
+		It("writes the hook into core.hooksPath", func() {
+			RunCommand("git", "-C", bed.Path(), "config", "core.hooksPath", ".githooks").ExpectSuccess()
+			Expect(runWithArgs("hooks", "install", bed.Path())).ToNot(HaveOccurred())
+			expectFileToContain(path.Join(bed.Path(), ".githooks", "commit-msg"), "lint --fail-no-match")
+		})
+
+		It("writes the hook shared by all worktrees from a linked worktree", func() {
+			bed.AddCommit("one")
+			worktree := createAnEmptyTempDir()
+			defer cleanupEmptyTempDir(worktree)
+			Expect(os.Remove(worktree)).To(Succeed())
+			RunCommand("git", "-C", bed.Path(), "worktree", "add", "--quiet", "--detach", worktree).ExpectSuccess()
+
+			Expect(runWithArgs("hooks", "install", worktree)).ToNot(HaveOccurred())
+			expectFileToContain(filename, "lint --fail-no-match")
+		})
+	})
+
+	Describe("config", func() {
+		It("requires a subcommand", func() {
+			Expect(runWithArgs("config", bed.Path())).To(HaveOccurred())
+		})
+
+		It("prints the configuration", func() {
+			Expect(runWithArgs("config", "print", "-f", "json", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(ContainSubstring(`"tag_prefix": "command line"`))
+		})
+
+		It("validates the configuration", func() {
+			Expect(runWithArgs("config", "validate", "-0", "bad-semver", bed.Path())).To(HaveOccurred())
+			Expect(rec.Stderr.String()).To(ContainSubstring("command line: initial_version"))
+		})
+
+		Describe("init", func() {
+			It("writes the project config file", func() {
+				Expect(runWithArgs("config", "init", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+				expectFileToContain(path.Join(bed.Path(), ".semver-bumper.conf.yaml"), "tag_prefix: v")
+
+				Expect(runWithArgs(bed.Path())).ToNot(HaveOccurred())
+				Expect(rec.Stdout.String()).To(Equal("1.3.0\n"))
+			})
+
+			It("does not overwrite an existing file without --force", func() {
+				Expect(runWithArgs("config", "init", "-t", "v", bed.Path())).ToNot(HaveOccurred())
+				Expect(runWithArgs("config", "init", "-t", "r", bed.Path())).To(HaveOccurred())
+				Expect(runWithArgs("config", "init", "--force", "-t", "r", bed.Path())).ToNot(HaveOccurred())
+				expectFileToContain(path.Join(bed.Path(), ".semver-bumper.conf.yaml"), "tag_prefix: r")
+			})
+		})
+	})
+
+	Describe("help", func() {
+		It("prints the help to stdout and succeeds", func() {
+			err := runWithArgs("--help")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ExitCode(err)).To(Equal(ExitCodeOk))
+			Expect(rec.Stdout.String()).To(HavePrefix("Usage:"))
+			Expect(rec.Stdout.String()).To(ContainSubstring("Available commands:"))
+		})
+
+		It("prints the help of a command", func() {
+			Expect(runWithArgs("tag", "-h")).ToNot(HaveOccurred())
+			Expect(rec.Stdout.String()).To(ContainSubstring("--dry-run"))
+		})
+	})
+
+	DescribeTable(
+		"ExitCode",
+		func(err error, expected int) {
+			Expect(ExitCode(err)).To(Equal(expected))
+		},
+		Entry("success", nil, ExitCodeOk),
+		Entry("usage error", &flags.Error{Type: flags.ErrUnknownFlag}, ExitCodeUsage),
+		Entry("nothing to release", fmt.Errorf("wrapped: %w", bumper.ErrNothingToRelease), ExitCodeNothingToRelease),
+		Entry("any other error", errors.New("expected"), ExitCodeError),
+	)
+})
@@ -0,0 +1,375 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/timotto/semver-bumper/pkg/bumper"
+	"github.com/timotto/semver-bumper/pkg/changelog"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	"github.com/timotto/semver-bumper/pkg/gitrepo"
+	gossh "golang.org/x/crypto/ssh"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+const (
+	concourseCheck = "check"
+	concourseIn    = "in"
+	concourseOut   = "out"
+)
+
+type (
+	// concourse runs a step of the Concourse resource protocol, it reads the request from stdin
+	// and writes the response to stdout
+	concourse struct {
+		os   Os
+		args []string
+		req  concourseRequest
+	}
+
+	// concourseSource is the source of the resource, the options are inline with the keys of the config file
+	concourseSource struct {
+		URI    string `json:"uri"`
+		Branch string `json:"branch,omitempty"`
+		// PrivateKey authenticates an ssh uri
+		PrivateKey string `json:"private_key,omitempty"`
+		// KnownHosts are the known_hosts lines verifying the host key of an ssh uri, it defaults to ~/.ssh/known_hosts,
+		// unless SkipHostKeyVerification is set
+		KnownHosts              string `json:"known_hosts,omitempty"`
+		SkipHostKeyVerification bool   `json:"skip_host_key_verification,omitempty"`
+		// Username and Password authenticate an http uri
+		Username string `json:"username,omitempty"`
+		Password string `json:"password,omitempty"`
+		Options
+	}
+
+	concourseVersion struct {
+		Version string `json:"version"`
+	}
+
+	concourseParams struct {
+		// Repository is the directory of the git repository to tag, relative to the sources directory
+		Repository string `json:"repository"`
+	}
+
+	concourseRequest struct {
+		Source  concourseSource   `json:"source"`
+		Version *concourseVersion `json:"version"`
+		Params  concourseParams   `json:"params"`
+	}
+
+	concourseMetadata struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	concourseResponse struct {
+		Version  concourseVersion    `json:"version"`
+		Metadata []concourseMetadata `json:"metadata"`
+	}
+)
+
+// isConcourse returns true if the program is called as the check, in, or out script of a Concourse resource
+func isConcourse(os Os) bool {
+	switch path.Base(os.Args()[0]) {
+	case concourseCheck, concourseIn, concourseOut:
+		return true
+	default:
+		return false
+	}
+}
+
+func runConcourse(os Os) error {
+	c := &concourse{os: os, args: os.Args()[1:]}
+	if err := json.NewDecoder(os.Stdin()).Decode(&c.req); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	if err := c.opts().Valid(); err != nil {
+		return err
+	}
+
+	switch path.Base(os.Args()[0]) {
+	case concourseCheck:
+		return c.check()
+	case concourseIn:
+		return c.in()
+	default:
+		return c.out()
+	}
+}
+
+func (c *concourse) opts() *Options {
+	return &c.req.Source.Options
+}
+
+// check emits the requested version and all later versions, or the latest version if none is requested
+func (c *concourse) check() error {
+	rt, cleanup, err := c.clone()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	versions, err := rt.repo.Versions()
+	if err != nil {
+		return err
+	}
+
+	result := []concourseVersion{}
+	if len(versions) == 0 {
+		return printJson(c.os, result)
+	}
+
+	s := c.opts().SchemeValue()
+	start := len(versions) - 1
+	if c.req.Version != nil {
+		for i, v := range versions {
+			if s.String(v.Version) == c.req.Version.Version {
+				start = i
+				break
+			}
+		}
+	}
+
+	for _, v := range versions[start:] {
+		result = append(result, concourseVersion{Version: s.String(v.Version)})
+	}
+
+	return printJson(c.os, result)
+}
+
+// in writes the version, the tag, the commit, and a changelog since the previous version into the destination directory
+func (c *concourse) in() error {
+	if len(c.args) == 0 {
+		return fmt.Errorf("missing destination directory")
+	}
+	if c.req.Version == nil {
+		return fmt.Errorf("missing version")
+	}
+
+	rt, cleanup, err := c.clone()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	versions, err := rt.repo.Versions()
+	if err != nil {
+		return err
+	}
+
+	s := c.opts().SchemeValue()
+	index := -1
+	for i, v := range versions {
+		if s.String(v.Version) == c.req.Version.Version {
+			index = i
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("version %v not found", c.req.Version.Version)
+	}
+
+	v := versions[index]
+	revisionRange := v.Commit.Hash.String()
+	if index > 0 {
+		revisionRange = versions[index-1].Commit.Hash.String() + ".." + revisionRange
+	}
+
+	commits, err := rt.repo.CommitsInRange(revisionRange)
+	if err != nil {
+		return err
+	}
+
+	var entries []changelog.Entry
+	for _, commit := range commits {
+		lvl, err := rt.commitLevel(commit)
+		if err != nil {
+			return err
+		}
+
+		text := fmt.Sprintf("%v (%v)", subject(commit.Message), commit.Hash.String()[:7])
+		entries = append(entries, changelog.Entry{Level: lvl, Text: text})
+	}
+
+	files := map[string]string{
+		"version":      s.String(v.Version),
+		"tag":          v.Tag,
+		"commit":       v.Commit.Hash.String(),
+		"changelog.md": changelog.Markdown(s.String(v.Version), entries),
+	}
+	for name, content := range files {
+		filename := filepath.Join(c.args[0], name)
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write to %v: %w", filename, err)
+		}
+	}
+
+	return c.respond(newVersionInfo(s, v))
+}
+
+// out tags the next version in the repository of the params and pushes the tag to the uri of the source,
+// the latest release is emitted without a new tag if there is nothing to release
+func (c *concourse) out() error {
+	if len(c.args) == 0 {
+		return fmt.Errorf("missing sources directory")
+	}
+	if c.req.Params.Repository == "" {
+		return fmt.Errorf("missing repository parameter")
+	}
+
+	rt, err := newRuntime(c.os, c.opts(), filepath.Join(c.args[0], c.req.Params.Repository))
+	if err != nil {
+		return err
+	}
+
+	result, err := rt.bump()
+	switch {
+	case errors.Is(err, bumper.ErrNothingToRelease):
+		Errln(c.os, "nothing to release, the latest release is", c.opts().SchemeValue().String(result.Version))
+
+	case err != nil:
+		return err
+
+	default:
+		tag, err := rt.repo.CreateTag(result.Version)
+		if err != nil {
+			return err
+		}
+		Errln(c.os, "created tag", tag)
+
+		if uri := c.req.Source.URI; uri != "" {
+			auth, err := c.req.Source.auth()
+			if err != nil {
+				return err
+			}
+			if err := rt.repo.PushTag(tag, uri, auth); err != nil {
+				return err
+			}
+			Errln(c.os, "pushed tag", tag, "to", uri)
+		}
+	}
+
+	v, err := taggedVersion(rt.repo, result.Version)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		return fmt.Errorf("nothing to release and version %v is not tagged in the repository", c.opts().SchemeValue().String(result.Version))
+	}
+
+	return c.respond(newVersionInfo(c.opts().SchemeValue(), *v))
+}
+
+// clone clones the repository of the source into a temporary directory, which is removed by the cleanup function
+func (c *concourse) clone() (*runtime, func(), error) {
+	if c.req.Source.URI == "" {
+		return nil, nil, fmt.Errorf("missing uri in source")
+	}
+
+	auth, err := c.req.Source.auth()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "semver-bumper-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot create a temporary directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+
+	repo, err := gitrepo.Clone(c.opts(), c.req.Source.URI, c.req.Source.Branch, dir, auth)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return newRuntimeOf(c.os, c.opts(), repo), cleanup, nil
+}
+
+// auth returns the credentials of the source, nil without credentials.
+// There are no known hosts in a resource container, so the host key of an ssh uri is not verified.
+func (s concourseSource) auth() (transport.AuthMethod, error) {
+	switch {
+	case s.PrivateKey != "":
+		auth, err := ssh.NewPublicKeys("git", []byte(s.PrivateKey), "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+
+		switch {
+		case s.SkipHostKeyVerification:
+			auth.HostKeyCallback = gossh.InsecureIgnoreHostKey()
+		case s.KnownHosts != "":
+			if auth.HostKeyCallback, err = knownHostsCallback(s.KnownHosts); err != nil {
+				return nil, err
+			}
+		}
+		return auth, nil
+
+	case s.Username != "" || s.Password != "":
+		return &http.BasicAuth{Username: s.Username, Password: s.Password}, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+// knownHostsCallback verifies the host keys with the given known_hosts lines,
+// they are read from a temporary file because knownhosts only reads files
+func knownHostsCallback(knownHosts string) (gossh.HostKeyCallback, error) {
+	file, err := os.CreateTemp("", "known_hosts-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create known hosts file: %w", err)
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	_, err = file.WriteString(knownHosts)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write known hosts file: %w", err)
+	}
+
+	callback, err := ssh.NewKnownHostsCallback(file.Name())
+	if err != nil {
+		return nil, fmt.Errorf("invalid known hosts: %w", err)
+	}
+
+	return callback, nil
+}
+
+func (c *concourse) respond(v *versionInfo) error {
+	return printJson(c.os, concourseResponse{
+		Version: concourseVersion{Version: v.Version},
+		Metadata: []concourseMetadata{
+			{Name: "tag", Value: v.Tag},
+			{Name: "commit", Value: v.Commit},
+			{Name: "date", Value: v.Date.Format(time.RFC3339)},
+		},
+	})
+}
+
+// taggedVersion returns the tagged version equal to the given version, nil if it is not tagged
+func taggedVersion(repo *gitrepo.Gitrepo, version *semver.Version) (*gitrepo.TaggedVersion, error) {
+	versions, err := repo.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range versions {
+		if v.Version.Equal(version) {
+			return &v, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,144 @@
+package cli_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/internal/cli"
+	. "github.com/timotto/semver-bumper/internal/cli/clifakes"
+	. "github.com/timotto/semver-bumper/pkg/test/git_testbed"
+	"os"
+	"path"
+	"strings"
+)
+
+var _ = Describe("Concourse resource", func() {
+	var (
+		bed    *TestbedRepo
+		fakeOs *FakeOs
+		rec    *outputRecorder
+		dir    string
+	)
+	BeforeEach(CreateBeforeEach(os.TempDir(), &bed))
+	AfterEach(TeardownAfterEach(&bed))
+	BeforeEach(func() {
+		dir = createAnEmptyTempDir()
+		bed.
+			AddCommits("initial").
+			AddLightweightTag("v1.2.3").
+			AddCommits("feat: expected feature").
+			AddLightweightTag("v1.3.0").
+			AddCommits("fix: expected fix")
+	})
+	AfterEach(func() {
+		cleanupEmptyTempDir(dir)
+	})
+
+	var runStep = func(step, request string, args ...string) error {
+		fakeOs, rec = newRecordingFakeOs(args...)
+		fakeOs.ArgsReturns(append([]string{"/opt/resource/" + step}, args...))
+		fakeOs.StdinReturns(strings.NewReader(request))
+		return Run(fakeOs)
+	}
+	var source = func() string {
+		return fmt.Sprintf(`{"uri":%q,"tag_prefix":"v"}`, bed.Path())
+	}
+
+	Describe("check", func() {
+		It("emits the latest version without a given version", func() {
+			Expect(runStep("check", `{"source":`+source()+`}`)).To(Succeed())
+			Expect(rec.Stdout.String()).To(MatchJSON(`[{"version":"1.3.0"}]`))
+		})
+
+		It("emits the given version and all later versions", func() {
+			Expect(runStep("check", `{"source":`+source()+`,"version":{"version":"1.2.3"}}`)).To(Succeed())
+			Expect(rec.Stdout.String()).To(MatchJSON(`[{"version":"1.2.3"},{"version":"1.3.0"}]`))
+		})
+
+		It("emits an empty list without versions", func() {
+			request := fmt.Sprintf(`{"source":{"uri":%q,"tag_prefix":"none-"}}`, bed.Path())
+			Expect(runStep("check", request)).To(Succeed())
+			Expect(rec.Stdout.String()).To(MatchJSON(`[]`))
+		})
+
+		It("rejects an invalid private key", func() {
+			request := fmt.Sprintf(`{"source":{"uri":%q,"private_key":"not a key"}}`, bed.Path())
+			Expect(runStep("check", request)).To(MatchError(ContainSubstring("invalid private key")))
+		})
+
+		It("rejects invalid known hosts", func() {
+			request := fmt.Sprintf(`{"source":{"uri":%q,"private_key":%q,"known_hosts":"not a host key"}}`, bed.Path(), aPrivateKey())
+			Expect(runStep("check", request)).To(MatchError(ContainSubstring("invalid known hosts")))
+		})
+
+		It("rejects invalid options", func() {
+			request := fmt.Sprintf(`{"source":{"uri":%q,"initial_version":"bad-semver"}}`, bed.Path())
+			Expect(runStep("check", request)).To(MatchError(ContainSubstring("invalid initial version")))
+		})
+	})
+
+	Describe("in", func() {
+		It("writes the version, the tag, the commit, and a changelog", func() {
+			Expect(runStep("in", `{"source":`+source()+`,"version":{"version":"1.3.0"}}`, dir)).To(Succeed())
+
+			var response map[string]interface{}
+			Expect(json.Unmarshal(rec.Stdout.Bytes(), &response)).To(Succeed())
+			Expect(response).To(HaveKeyWithValue("version", map[string]interface{}{"version": "1.3.0"}))
+
+			var readFile = func(name string) string {
+				data, err := os.ReadFile(path.Join(dir, name))
+				Expect(err).ToNot(HaveOccurred())
+				return string(data)
+			}
+			Expect(readFile("version")).To(Equal("1.3.0"))
+			Expect(readFile("tag")).To(Equal("v1.3.0"))
+			Expect(readFile("commit")).To(Equal(bed.Commits()[1].Hash.String()))
+			Expect(readFile("changelog.md")).To(ContainSubstring("feat: expected feature"))
+			Expect(readFile("changelog.md")).ToNot(ContainSubstring("initial"))
+		})
+
+		It("fails for an unknown version", func() {
+			Expect(runStep("in", `{"source":`+source()+`,"version":{"version":"9.9.9"}}`, dir)).To(MatchError(ContainSubstring("not found")))
+		})
+	})
+
+	Describe("out", func() {
+		BeforeEach(func() {
+			_, err := git.PlainClone(path.Join(dir, "repo"), false, &git.CloneOptions{URL: bed.Path()})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("tags the next version and pushes the tag to the uri", func() {
+			Expect(runStep("out", `{"source":`+source()+`,"params":{"repository":"repo"}}`, dir)).To(Succeed())
+			Expect(rec.Stdout.String()).To(ContainSubstring(`"version": "1.3.1"`))
+
+			Expect(runStep("check", `{"source":`+source()+`}`)).To(Succeed())
+			Expect(rec.Stdout.String()).To(MatchJSON(`[{"version":"1.3.1"}]`))
+		})
+
+		It("emits the latest release if there is nothing to release", func() {
+			bed.AddLightweightTag("v1.3.1")
+			request := fmt.Sprintf(`{"source":{"tag_prefix":"v"},"params":{"repository":%q}}`, path.Base(bed.Path()))
+			Expect(runStep("out", request, path.Dir(bed.Path()))).To(Succeed())
+			Expect(rec.Stdout.String()).To(ContainSubstring(`"version": "1.3.1"`))
+		})
+
+		It("requires the repository parameter", func() {
+			Expect(runStep("out", `{"source":`+source()+`}`, dir)).To(MatchError(ContainSubstring("missing repository")))
+		})
+	})
+})
+
+// aPrivateKey is a PEM encoded RSA private key
+func aPrivateKey() string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	Expect(err).ToNot(HaveOccurred())
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
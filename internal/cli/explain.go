@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/timotto/semver-bumper/pkg/bumper"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"strings"
+)
+
+func (rt runtime) explain(result *bumper.Result) {
+	if !rt.opts.Explain {
+		return
+	}
+
+	Errln(rt.os, "version:", rt.opts.SchemeValue().String(result.Version))
+
+	var changes []bumper.Reason
+	Errln(rt.os, "commits:")
+	for _, reason := range result.Reasons {
+		if reason.Commit == nil {
+			changes = append(changes, reason)
+			continue
+		}
+
+		Errln(rt.os, fmt.Sprintf("\t%-5v %v %v", reason.Level, reason.Commit.Hash.String()[:7], reason.Description))
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+
+	Errln(rt.os, "changes:")
+	for _, change := range changes {
+		Errln(rt.os, fmt.Sprintf("\t%-5v %v", change.Level, change))
+	}
+}
+
+func subject(message string) string {
+	return strings.SplitN(strings.TrimSpace(message), "\n", 2)[0]
+}
+
+func (rt runtime) commitLevel(commit *object.Commit) (BumpLevel, error) {
+	limits, err := rt.repo.LevelLimits(commit)
+	if err != nil {
+		return BumpLevelNone, err
+	}
+
+	return limits.Apply(rt.esti.CommitBumpLevel(commit.Message)), nil
+}
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	"github.com/timotto/semver-bumper/pkg/estimator"
+	"github.com/timotto/semver-bumper/pkg/gitrepo"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	stdinFilename = "-"
+
+	// gitScissors starts the diff of "git commit --verbose", it is not part of the message
+	gitScissors = "# ------------------------ >8 ------------------------"
+
+	// commitMsgHook calls the executable running hooks install
+	commitMsgHook = `#!/bin/sh
+# installed by semver-bumper hooks install
+exec %v lint --fail-no-match --file "$1"
+`
+)
+
+func (c *lintCommand) Execute(args []string) error {
+	if c.File != "" {
+		return c.lintMessageFile(args)
+	}
+
+	rt, err := c.app.runtime(args)
+	if err != nil {
+		return err
+	}
+
+	commits, err := c.commits(rt)
+	if err != nil {
+		return err
+	}
+
+	var unmatched []*object.Commit
+	for _, commit := range commits {
+		lvl, err := rt.commitLevel(commit)
+		if err != nil {
+			return err
+		}
+		if rt.esti.CommitBumpLevel(commit.Message) == BumpLevelNone {
+			unmatched = append(unmatched, commit)
+		}
+
+		Outln(rt.os, fmt.Sprintf("%-5v %v %v", lvl, commit.Hash.String()[:7], subject(commit.Message)))
+	}
+
+	if c.app.opts.FailNoMatch && len(unmatched) > 0 {
+		return fmt.Errorf("%v of %v commits match no keyword", len(unmatched), len(commits))
+	}
+
+	return nil
+}
+
+func (c *lintCommand) commits(rt *runtime) ([]*object.Commit, error) {
+	if c.Range != "" {
+		return rt.repo.CommitsInRange(c.Range)
+	}
+
+	latest, err := rt.repo.LatestTaggedRelease()
+	if err != nil {
+		return nil, err
+	}
+
+	return rt.repo.CommitMessagesSince(latest)
+}
+
+// lintMessageFile does not need the git repository, only its config files
+func (c *lintCommand) lintMessageFile(args []string) error {
+	gitRepoPath, err := GitRepoPath(args)
+	if err != nil {
+		return err
+	}
+
+	if err := readOptions(c.app.os, c.app.opts, gitRepoPath); err != nil {
+		return err
+	}
+
+	message, err := c.readMessage()
+	if err != nil {
+		return err
+	}
+
+	lvl := estimator.NewEstimator(c.app.opts).WithLogger(newLogger(c.app.os, c.app.opts)).CommitBumpLevel(message)
+	Outln(c.app.os, fmt.Sprintf("%-5v %v", lvl, subject(message)))
+
+	if c.app.opts.FailNoMatch && lvl == BumpLevelNone {
+		return fmt.Errorf("the commit message matches no keyword, the keywords are:\n%v", strings.TrimSuffix(keywordList(c.app.opts), "\n"))
+	}
+
+	return nil
+}
+
+func (c *lintCommand) readMessage() (string, error) {
+	var data []byte
+	var err error
+	if c.File == stdinFilename {
+		data, err = io.ReadAll(c.app.os.Stdin())
+	} else {
+		data, err = os.ReadFile(c.File)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit message: %w", err)
+	}
+
+	return cleanMessage(string(data)), nil
+}
+
+// cleanMessage removes the comments git adds to the message file
+func cleanMessage(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if line == gitScissors {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func (c *hooksInstallCommand) Execute(args []string) error {
+	gitRepoPath, err := GitRepoPath(args)
+	if err != nil {
+		return err
+	}
+
+	repo, err := gitrepo.NewGitRepo(&Options{}, gitRepoPath)
+	if err != nil {
+		return err
+	}
+
+	dir, err := repo.HooksDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %v: %w", dir, err)
+	}
+
+	filename := path.Join(dir, "commit-msg")
+	if _, err := os.Stat(filename); err == nil && !c.Force {
+		return fmt.Errorf("hook %v exists, use --force to overwrite it", filename)
+	}
+
+	if err := os.WriteFile(filename, []byte(fmt.Sprintf(commitMsgHook, shellQuote(hookExecutable()))), 0755); err != nil {
+		return fmt.Errorf("failed to write %v: %w", filename, err)
+	}
+
+	Outln(c.app.os, "commit-msg hook written to", filename)
+
+	return nil
+}
+
+// hookExecutable is the path of the running executable, the release binaries are named after the platform,
+// eg semver-bumper-linux-amd64, or semver-bumper in the PATH if the path is unknown
+func hookExecutable() string {
+	executable, err := os.Executable()
+	if err != nil {
+		return "semver-bumper"
+	}
+
+	return executable
+}
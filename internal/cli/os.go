@@ -3,6 +3,7 @@ package cli
 import (
 	"io"
 	"os"
+	"time"
 )
 
 type OS struct {
@@ -12,6 +13,18 @@ func (o OS) Args() []string {
 	return os.Args
 }
 
+func (o OS) Environ() []string {
+	return os.Environ()
+}
+
+func (o OS) Now() time.Time {
+	return time.Now()
+}
+
+func (o OS) Stdin() io.Reader {
+	return os.Stdin
+}
+
 func (o OS) Stdout() io.Writer {
 	return os.Stdout
 }
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/timotto/semver-bumper/pkg/bumper"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	"github.com/timotto/semver-bumper/pkg/scheme"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	githubOutputEnv = "GITHUB_OUTPUT"
+
+	// githubOutputDelimiter ends a value of the multi-line $GITHUB_OUTPUT format
+	githubOutputDelimiter = "SEMVER_BUMPER_EOF"
+)
+
+// outputValue is a key and value of the key/value output formats
+type outputValue struct {
+	key, value string
+}
+
+func (rt runtime) outputResult(result *bumper.Result) error {
+	data, err := encodeResult(rt.opts, result)
+	if err != nil {
+		return err
+	}
+
+	filename := rt.opts.Output
+	if filename == "" && rt.opts.OutputFormatValue() == OutputFormatGithub {
+		filename = getenv(rt.os, githubOutputEnv)
+	}
+
+	if filename == "" {
+		must(rt.os.Stdout().Write(data))
+		return nil
+	}
+
+	// other steps write into $GITHUB_OUTPUT as well
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if rt.opts.OutputFormatValue() == OutputFormatGithub {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	f, err := os.OpenFile(filename, flags, 0644)
+	if err == nil {
+		_, err = f.Write(data)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write to %v: %w", filename, err)
+	}
+
+	return nil
+}
+
+func encodeResult(opts *Options, result *bumper.Result) ([]byte, error) {
+	buf := &strings.Builder{}
+	switch opts.OutputFormatValue() {
+	case OutputFormatDotenv, OutputFormatGitlab:
+		for _, v := range outputValues(opts, result) {
+			_, _ = fmt.Fprintf(buf, "%v=%v\n", v.key, v.value)
+		}
+
+	case OutputFormatExport:
+		for _, v := range outputValues(opts, result) {
+			_, _ = fmt.Fprintf(buf, "export %v=%v\n", v.key, shellQuote(v.value))
+		}
+
+	case OutputFormatGithub:
+		for _, v := range outputValues(opts, result) {
+			if strings.Contains(v.value, githubOutputDelimiter) {
+				return nil, fmt.Errorf("the value of %v contains the delimiter %v", v.key, githubOutputDelimiter)
+			}
+			_, _ = fmt.Fprintf(buf, "%v<<%v\n%v\n%v\n", v.key, githubOutputDelimiter, v.value, githubOutputDelimiter)
+		}
+
+	default:
+		buf.WriteString(opts.SchemeValue().String(result.Version) + "\n")
+	}
+
+	return []byte(buf.String()), nil
+}
+
+// outputValues are the values of the key/value output formats, the previous version of a prerelease is the latest prerelease
+func outputValues(opts *Options, result *bumper.Result) []outputValue {
+	previous := result.LatestRelease
+	if opts.BumpPrerelease() {
+		previous = result.LatestPrerelease
+	}
+
+	s := opts.SchemeValue()
+	return []outputValue{
+		{"VERSION", s.String(result.Version)},
+		{"PREVIOUS_VERSION", versionString(s, previous)},
+		{"BUMP_LEVEL", result.Level.String()},
+		{"TAG", opts.TagPrefix + s.String(result.Version)},
+		{"IS_PRERELEASE", strconv.FormatBool(result.Version.Prerelease() != "")},
+	}
+}
+
+func versionString(s scheme.Scheme, v *semver.Version) string {
+	if v == nil {
+		return ""
+	}
+
+	return s.String(v)
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/timotto/semver-bumper/pkg/bumper"
+	"github.com/timotto/semver-bumper/pkg/gitrepo"
+	"time"
+)
+
+const (
+	replayEveryMerge = "merge"
+
+	dateLayout = "2006-01-02"
+)
+
+func (c *replayCommand) Execute(args []string) error {
+	dates, err := parseDates(c.Dates)
+	if err != nil {
+		return err
+	}
+
+	rt, err := c.app.runtime(args)
+	if err != nil {
+		return err
+	}
+
+	var since *object.Commit
+	if c.From != "" {
+		if since, err = rt.repo.CommitOf(c.From); err != nil {
+			return err
+		}
+	}
+
+	history, err := rt.repo.NewHistory(since)
+	if err != nil {
+		return err
+	}
+
+	firstParents, err := history.FirstParents()
+	if err != nil {
+		return err
+	}
+
+	released, err := releasedCommits(rt.repo)
+	if err != nil {
+		return err
+	}
+
+	at := c.releaseCandidates(firstParents, dates, released)
+	releases, err := bumper.Replay(rt.opts, history, rt.esti, since, at, released)
+	if err != nil {
+		return err
+	}
+
+	var planned []gitrepo.TaggedVersion
+	for _, release := range releases {
+		tag := rt.opts.TagPrefix + rt.opts.SchemeValue().String(release.Version)
+		planned = append(planned, gitrepo.TaggedVersion{Version: release.Version, Tag: tag, Commit: release.Commit})
+	}
+
+	if c.Apply {
+		if planned, err = rt.repo.CreateTagsAt(planned); err != nil {
+			return err
+		}
+	}
+
+	result := []*versionInfo{}
+	for _, v := range planned {
+		result = append(result, newVersionInfo(rt.opts.SchemeValue(), v))
+	}
+
+	if c.Format == formatJson {
+		return printJson(rt.os, result)
+	}
+
+	for _, v := range result {
+		Outln(rt.os, v.String())
+	}
+
+	return nil
+}
+
+// releaseCandidates selects the commits of the first parent chain where a release could have occurred,
+// commits with a release tag are always included to continue the replay from their version
+func (c *replayCommand) releaseCandidates(firstParents []*object.Commit, dates []time.Time, released map[plumbing.Hash]*semver.Version) []*object.Commit {
+	candidates := make(map[plumbing.Hash]bool)
+	switch {
+	case len(dates) > 0:
+		for _, date := range dates {
+			var last *object.Commit
+			for _, commit := range firstParents {
+				if commit.Committer.When.After(date) {
+					break
+				}
+				last = commit
+			}
+			if last != nil {
+				candidates[last.Hash] = true
+			}
+		}
+
+	case c.Every == replayEveryMerge:
+		for _, commit := range firstParents {
+			if commit.NumParents() > 1 {
+				candidates[commit.Hash] = true
+			}
+		}
+
+	default:
+		for _, commit := range firstParents {
+			candidates[commit.Hash] = true
+		}
+	}
+
+	var result []*object.Commit
+	for _, commit := range firstParents {
+		if _, ok := released[commit.Hash]; ok || candidates[commit.Hash] {
+			result = append(result, commit)
+		}
+	}
+
+	return result
+}
+
+// releasedCommits maps the commits to their release version, prereleases are ignored
+func releasedCommits(repo *gitrepo.Gitrepo) (map[plumbing.Hash]*semver.Version, error) {
+	versions, err := repo.Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[plumbing.Hash]*semver.Version)
+	for _, v := range versions {
+		if v.Version.Prerelease() == "" {
+			result[v.Commit.Hash] = v.Version
+		}
+	}
+
+	return result, nil
+}
+
+// parseDates accepts RFC 3339 timestamps and dates, a date includes the whole day in UTC
+func parseDates(values []string) ([]time.Time, error) {
+	var result []time.Time
+	for _, value := range values {
+		if date, err := time.Parse(time.RFC3339, value); err == nil {
+			result = append(result, date)
+		} else if date, err := time.Parse(dateLayout, value); err == nil {
+			result = append(result, date.Add(24*time.Hour-time.Nanosecond))
+		} else {
+			return nil, fmt.Errorf("invalid date %v, expected eg %v or %v", value, dateLayout, time.RFC3339)
+		}
+	}
+
+	return result, nil
+}
@@ -3,45 +3,41 @@ package cli
 import (
 	"bytes"
 	"fmt"
-	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/timotto/semver-bumper/pkg/bumper"
+	"github.com/timotto/semver-bumper/pkg/changelog"
+	. "github.com/timotto/semver-bumper/pkg/config"
 	"os"
 	"strings"
 )
 
-func (rt runtime) beforeResult() error {
+// beforeResult runs the actions which replace the result, it returns true if one did
+func (rt runtime) beforeResult() (bool, error) {
 	if rt.opts.PrintKeywords {
-		return rt.printKeywords()
+		return true, rt.printKeywords()
 	}
 
 	if rt.opts.WriteConfigFile() {
-		return rt.writeConfigToFile()
+		return true, rt.writeConfigToFile()
 	}
 
-	return nil
+	return false, nil
 }
 
-func (rt runtime) onResult(version *semver.Version, commits []*object.Commit) error {
-	if err := rt.outputVersion(version); err != nil {
+func (rt runtime) onResult(result *bumper.Result) error {
+	if err := rt.outputResult(result); err != nil {
 		return err
 	}
 
-	if err := rt.outputCommits(commits); err != nil {
+	if err := rt.outputCommits(result.Commits); err != nil {
 		return err
 	}
 
-	return nil
-}
-
-func (rt runtime) outputVersion(version *semver.Version) error {
-	if rt.opts.Output == "" {
-		Outln(rt.os, version.String())
-		return nil
+	if err := rt.outputChangelog(result); err != nil {
+		return err
 	}
 
-	if err := os.WriteFile(rt.opts.Output, []byte(version.String()+"\n"), 0644); err != nil {
-		return fmt.Errorf("failed to write to %v: %w", rt.opts.Output, err)
-	}
+	rt.explain(result)
 
 	return nil
 }
@@ -59,6 +55,35 @@ func (rt runtime) outputCommits(commits []*object.Commit) error {
 	return nil
 }
 
+func (rt runtime) outputChangelog(result *bumper.Result) error {
+	if !rt.opts.OutputChangelog() {
+		return nil
+	}
+
+	data := []byte(changelog.Markdown(rt.opts.SchemeValue().String(result.Version), changelogEntries(result)))
+	if err := os.WriteFile(rt.opts.Changelog, data, 0644); err != nil {
+		return fmt.Errorf("failed to write to %v: %w", rt.opts.Changelog, err)
+	}
+
+	return nil
+}
+
+// changelogEntries lists the detected changes before the commits
+func changelogEntries(result *bumper.Result) []changelog.Entry {
+	var changes, commits []changelog.Entry
+	for _, reason := range result.Reasons {
+		if reason.Commit == nil {
+			changes = append(changes, changelog.Entry{Level: reason.Level, Text: reason.String()})
+			continue
+		}
+
+		text := fmt.Sprintf("%v (%v)", reason.Description, reason.Commit.Hash.String()[:7])
+		commits = append(commits, changelog.Entry{Level: reason.Level, Text: text})
+	}
+
+	return append(changes, commits...)
+}
+
 func format(commits []*object.Commit) string {
 	var lines []string
 	for _, commit := range commits {
@@ -74,8 +99,14 @@ func format(commits []*object.Commit) string {
 }
 
 func (rt runtime) printKeywords() error {
+	Outln(rt.os, "the keywords for the different version bump level are:")
+	must(fmt.Fprint(rt.os.Stdout(), keywordList(rt.opts)))
+
+	return nil
+}
+
+func keywordList(opts *Options) string {
 	buf := &bytes.Buffer{}
-	_, _ = fmt.Fprintln(buf, "the keywords for the different version bump level are:")
 	var fn = func(label string, keywords []string) {
 		_, _ = fmt.Fprintf(buf, "%s:\n", label)
 		for _, key := range keywords {
@@ -83,17 +114,19 @@ func (rt runtime) printKeywords() error {
 		}
 	}
 
-	fn("major", rt.opts.KeywordsMajor)
-	fn("minor", rt.opts.KeywordsMinor)
-	fn("patch", rt.opts.KeywordsPatch)
+	fn("major", opts.KeywordsMajor)
+	fn("minor", opts.KeywordsMinor)
+	fn("patch", opts.KeywordsPatch)
 
-	return fmt.Errorf(buf.String())
+	return buf.String()
 }
 
 func (rt runtime) writeConfigToFile() error {
 	if err := rt.opts.WriteToFile(rt.opts.WriteConfig); err != nil {
 		return err
-	} else {
-		return fmt.Errorf("configuration written to %v", rt.opts.WriteConfig)
 	}
+
+	Outln(rt.os, "configuration written to", rt.opts.WriteConfig)
+
+	return nil
 }
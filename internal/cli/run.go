@@ -1,20 +1,28 @@
 package cli
 
-import "github.com/timotto/semver-bumper/pkg/bumper"
+import (
+	"errors"
+	"github.com/timotto/semver-bumper/pkg/bumper"
+)
 
 func (rt runtime) run() error {
-	if err := rt.beforeResult(); err != nil {
+	if done, err := rt.beforeResult(); done || err != nil {
 		return err
 	}
 
-	version, commits, err := bumper.Bump(rt.opts, rt.repo, rt.esti)
-	if err != nil {
-		return err
+	// the unchanged version is still written when there is nothing to release
+	result, bumpErr := rt.bump()
+	if bumpErr != nil && !errors.Is(bumpErr, bumper.ErrNothingToRelease) {
+		return bumpErr
 	}
 
-	if err := rt.onResult(version, commits); err != nil {
+	if err := rt.onResult(result); err != nil {
 		return err
 	}
 
-	return nil
+	return bumpErr
+}
+
+func (rt runtime) bump() (*bumper.Result, error) {
+	return bumper.Compute(rt.opts, rt.repo, rt.esti, rt.analyzers...)
 }
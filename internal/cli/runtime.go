@@ -3,83 +3,113 @@ package cli
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
 
 import (
+	"errors"
 	"github.com/timotto/semver-bumper/pkg/bumper"
 	. "github.com/timotto/semver-bumper/pkg/config"
 	"github.com/timotto/semver-bumper/pkg/estimator"
 	"github.com/timotto/semver-bumper/pkg/gitrepo"
+	"github.com/timotto/semver-bumper/pkg/logger"
+	"github.com/timotto/semver-bumper/pkg/semverbumper"
 	"io"
+	"time"
 )
 
 type runtime struct {
-	os   Os
-	opts *Options
-	repo bumper.GitRepo
-	esti bumper.Estimator
+	os        Os
+	opts      *Options
+	repo      *gitrepo.Gitrepo
+	esti      bumper.Estimator
+	log       *logger.Logger
+	analyzers []bumper.Analyzer
 }
 
 //counterfeiter:generate . Os
 type Os interface {
 	Args() []string
+	Environ() []string
+	Now() time.Time
+	Stdin() io.Reader
 	Stdout() io.Writer
 	Stderr() io.Writer
 }
 
-func newRuntime(os Os) (*runtime, error) {
-	opts, gitRepoPath, err := readOptions(os)
-	if err != nil {
-		return nil, err
-	}
-
+// newRuntime expects the options to be read and valid
+func newRuntime(os Os, opts *Options, gitRepoPath string) (*runtime, error) {
 	repo, err := gitrepo.NewGitRepo(opts, gitRepoPath)
 	if err != nil {
 		return nil, err
 	}
 
-	esti := estimator.NewEstimator(opts)
+	return newRuntimeOf(os, opts, repo), nil
+}
 
-	rt := &runtime{
-		os:   os,
-		opts: opts,
-		repo: repo,
-		esti: esti,
+func newRuntimeOf(os Os, opts *Options, repo *gitrepo.Gitrepo) *runtime {
+	log := newLogger(os, opts)
+	opts.WithNow(os.Now).WithLogger(log)
+
+	return &runtime{
+		os:        os,
+		opts:      opts,
+		repo:      repo.WithLogger(log),
+		esti:      estimator.NewEstimator(opts).WithLogger(log),
+		log:       log,
+		analyzers: semverbumper.Analyzers(opts, repo),
 	}
-	return rt, nil
 }
 
-func readOptions(os Os) (*Options, string, error) {
-	opts, gitRepoPath, err := readCliArgs(os)
-	if err != nil {
-		return nil, "", err
+func newLogger(os Os, opts *Options) *logger.Logger {
+	return logger.New(os.Stderr(), opts.LogLevelValue(), opts.LogFormatValue())
+}
+
+// readOptions completes the command line options with the environment and the config files, in that order
+func readOptions(os Os, opts *Options, gitRepoPath string) error {
+	if err := readEnv(os, opts); err != nil {
+		return err
 	}
 
-	return opts, gitRepoPath, opts.Valid()
+	if err := readConfigFiles(os, gitRepoPath, opts); err != nil {
+		return err
+	}
+
+	return opts.Valid()
 }
 
-func readCliArgs(os Os) (*Options, string, error) {
-	opts, gitRepoPath, err := FromOsArgs(os.Args())
+func readEnv(os Os, opts *Options) error {
+	env, err := FromEnv(os.Environ())
 	if err != nil {
-		return nil, "", err
+		return err
 	}
 
-	return opts, gitRepoPath, maybeReadConfigFile(gitRepoPath, opts)
+	opts.SetMissingFrom(env)
+
+	return nil
 }
 
-func maybeReadConfigFile(gitRepoPath string, opts *Options) error {
-	filename, err := SearchConfigFile(gitRepoPath, opts.ConfigFile)
+// readConfigFiles completes the options with all config files, reporting the problems of all files at once
+func readConfigFiles(os Os, gitRepoPath string, opts *Options) error {
+	filenames, err := SearchConfigFiles(os.Environ(), gitRepoPath, opts.ConfigFile)
 	if err != nil {
 		return err
 	}
 
-	if filename == "" {
-		return nil
+	var problems Problems
+	for _, filename := range filenames {
+		cfg, err := FromFile(filename)
+		var more Problems
+		switch {
+		case errors.As(err, &more):
+			problems = append(problems, more...)
+			continue
+		case err != nil:
+			return err
+		}
+
+		opts.SetMissingFrom(cfg)
 	}
 
-	cfg, err := FromFile(filename)
-	if err != nil {
-		return err
+	if len(problems) > 0 {
+		return problems
 	}
 
-	opts.SetMissingFrom(cfg)
-
 	return nil
 }
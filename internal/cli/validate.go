@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"errors"
+	. "github.com/timotto/semver-bumper/pkg/config"
+)
+
+// validateConfig reports the problems of the command line, the environment and the config files at once
+func validateConfig(os Os, opts *Options, gitRepoPath string) error {
+	var problems Problems
+	var collect = func(err error) {
+		var more Problems
+		if errors.As(err, &more) {
+			problems = append(problems, more...)
+		} else if err != nil {
+			problems = append(problems, Problem{Err: err})
+		}
+	}
+
+	collect(opts.Validate(FlagSource))
+	_, err := FromEnv(os.Environ())
+	collect(err)
+	if len(problems) == 0 {
+		collect(readOptions(os, opts, gitRepoPath))
+	} else {
+		collect(readConfigFiles(os, gitRepoPath, &Options{}))
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+
+	Outln(os, "the configuration is valid")
+
+	return nil
+}
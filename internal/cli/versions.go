@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/timotto/semver-bumper/pkg/gitrepo"
+	"github.com/timotto/semver-bumper/pkg/scheme"
+	"time"
+)
+
+const formatJson = "json"
+
+// versionInfo is the output of a tagged version
+type versionInfo struct {
+	Version string    `json:"version"`
+	Tag     string    `json:"tag"`
+	Commit  string    `json:"commit"`
+	Date    time.Time `json:"date"`
+}
+
+func newVersionInfo(s scheme.Scheme, v gitrepo.TaggedVersion) *versionInfo {
+	return &versionInfo{
+		Version: s.String(v.Version),
+		Tag:     v.Tag,
+		Commit:  v.Commit.Hash.String(),
+		Date:    v.Commit.Committer.When.UTC(),
+	}
+}
+
+func (v versionInfo) String() string {
+	return fmt.Sprintf("%v\t%v\t%v\t%v", v.Version, v.Tag, v.Commit[:7], v.Date.Format(time.RFC3339))
+}
+
+func (c *currentCommand) Execute(args []string) error {
+	rt, err := c.app.runtime(args)
+	if err != nil {
+		return err
+	}
+
+	versions, err := rt.repo.Versions()
+	if err != nil {
+		return err
+	}
+
+	var current struct {
+		Release    *versionInfo `json:"release,omitempty"`
+		Prerelease *versionInfo `json:"prerelease,omitempty"`
+	}
+	// the versions are in ascending order, a release supersedes the prereleases before it
+	for _, v := range versions {
+		if v.Version.Prerelease() == "" {
+			current.Release = newVersionInfo(rt.opts.SchemeValue(), v)
+			current.Prerelease = nil
+		} else {
+			current.Prerelease = newVersionInfo(rt.opts.SchemeValue(), v)
+		}
+	}
+
+	if current.Release == nil && current.Prerelease == nil {
+		return fmt.Errorf("there is no release yet")
+	}
+
+	if c.Format == formatJson {
+		return printJson(rt.os, current)
+	}
+
+	if current.Release != nil {
+		Outln(rt.os, "release\t"+current.Release.String())
+	}
+	if current.Prerelease != nil {
+		Outln(rt.os, "prerelease\t"+current.Prerelease.String())
+	}
+
+	return nil
+}
+
+func (c *listCommand) Execute(args []string) error {
+	var constraint *semver.Constraints
+	if c.Filter != "" {
+		var err error
+		if constraint, err = semver.NewConstraint(c.Filter); err != nil {
+			return fmt.Errorf("invalid filter %v: %w", c.Filter, err)
+		}
+	}
+
+	rt, err := c.app.runtime(args)
+	if err != nil {
+		return err
+	}
+
+	versions, err := rt.repo.Versions()
+	if err != nil {
+		return err
+	}
+
+	result := []*versionInfo{}
+	for _, v := range versions {
+		if constraint == nil || constraint.Check(v.Version) {
+			result = append(result, newVersionInfo(rt.opts.SchemeValue(), v))
+		}
+	}
+
+	if c.Format == formatJson {
+		return printJson(rt.os, result)
+	}
+
+	for _, v := range result {
+		Outln(rt.os, v.String())
+	}
+
+	return nil
+}
+
+func printJson(os Os, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+
+	Outln(os, string(data))
+
+	return nil
+}
@@ -0,0 +1,586 @@
+package apidiff
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	kindFunc symbolKind = iota
+	kindMethod
+	kindType
+	kindField
+	kindInterfaceMethod
+	kindConst
+	kindVar
+)
+
+const goModFile = "go.mod"
+
+type (
+	// api holds the exported symbols of each package directory
+	api        map[string]packageApi
+	packageApi map[string]symbol
+
+	symbol struct {
+		kind  symbolKind
+		owner string
+		// typ is the type of the symbol, the signature of funcs and methods
+		// or the underlying type of a type declaration
+		typ types.Type
+		// decl is "struct" or "interface" for those type declarations, "=" for an alias
+		decl string
+		// tparams are the type parameters of a generic type declaration
+		tparams *types.TypeParamList
+		// pointer is true for a method with a pointer receiver
+		pointer bool
+		// text is the type as written in the package, for the change descriptions
+		text string
+	}
+	symbolKind int
+)
+
+// parseApi type-checks the packages of the file set. Imports of packages within the module, read from
+// go.mod, are type-checked from the file set, all other imports are replaced by stub packages.
+func parseApi(files map[string][]byte) (api, error) {
+	fset := token.NewFileSet()
+	dirs := make(map[string][]*ast.File)
+	for _, name := range sortedNames(files) {
+		if !strings.HasSuffix(name, ".go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, name, files[name], 0)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %v: %w", name, err)
+		}
+
+		if file.Name.Name == "main" {
+			continue
+		}
+
+		dir := path.Dir(name)
+		dirs[dir] = append(dirs[dir], file)
+	}
+
+	imp := newSourceImporter(fset, modulePath(files[goModFile]), dirs)
+	result := make(api)
+	for dir := range dirs {
+		pkg, err := imp.Import(imp.packagePath(dir))
+		if err != nil {
+			return nil, err
+		}
+
+		result[dir] = newPackageApi(pkg)
+	}
+
+	return result, nil
+}
+
+func isGoSource(name string) bool {
+	if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+		return false
+	}
+
+	for _, element := range strings.Split(path.Dir(name), "/") {
+		switch {
+		case element == "internal", element == "testdata", element == "vendor":
+			return false
+		case strings.HasPrefix(element, "."), strings.HasPrefix(element, "_"):
+			if element != "." {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isApiSource accepts the Go sources and the go.mod file at the root, which names the module
+func isApiSource(name string) bool {
+	return name == goModFile || isGoSource(name)
+}
+
+func modulePath(goMod []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(goMod))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "module" {
+			if unquoted, err := strconv.Unquote(fields[1]); err == nil {
+				return unquoted
+			}
+			return fields[1]
+		}
+	}
+
+	return ""
+}
+
+func newPackageApi(pkg *types.Package) packageApi {
+	p := make(packageApi)
+	qualifier := func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		return other.Name()
+	}
+	text := func(t types.Type) string {
+		return types.TypeString(t, qualifier)
+	}
+
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+
+		switch o := obj.(type) {
+		case *types.Func:
+			p["func "+name] = symbol{kind: kindFunc, typ: o.Type(), text: text(o.Type())}
+
+		case *types.Const:
+			p["const "+name] = symbol{kind: kindConst, typ: o.Type(), text: text(o.Type())}
+
+		case *types.Var:
+			p["var "+name] = symbol{kind: kindVar, typ: o.Type(), text: text(o.Type())}
+
+		case *types.TypeName:
+			p.addType(o, text)
+		}
+	}
+
+	return p
+}
+
+func (p packageApi) addType(o *types.TypeName, text func(types.Type) string) {
+	name := o.Name()
+	if o.IsAlias() {
+		p["type "+name] = symbol{kind: kindType, typ: o.Type(), decl: "=", text: "= " + text(o.Type())}
+		return
+	}
+
+	named, _ := o.Type().(*types.Named)
+	var tparams *types.TypeParamList
+	if named != nil {
+		tparams = named.TypeParams()
+	}
+	prefix := typeParamsText(tparams, text)
+
+	switch t := o.Type().Underlying().(type) {
+	case *types.Struct:
+		p["type "+name] = symbol{kind: kindType, decl: "struct", tparams: tparams, text: prefix + "struct"}
+		for i := 0; i < t.NumFields(); i++ {
+			if field := t.Field(i); field.Exported() {
+				p["field "+name+"."+field.Name()] = symbol{kind: kindField, owner: name, typ: field.Type(), text: text(field.Type())}
+			}
+		}
+
+	case *types.Interface:
+		typeSet, typeSetText := interfaceTypeSet(t), "interface"
+		if typeSet.NumEmbeddeds() > 0 {
+			typeSetText = text(typeSet)
+		}
+		p["type "+name] = symbol{kind: kindType, typ: typeSet, decl: "interface", tparams: tparams, text: prefix + typeSetText}
+		for i := 0; i < t.NumExplicitMethods(); i++ {
+			if method := t.ExplicitMethod(i); method.Exported() {
+				p["method "+name+"."+method.Name()] = symbol{kind: kindInterfaceMethod, owner: name, typ: method.Type(), text: text(method.Type())}
+			}
+		}
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			embedded := t.EmbeddedType(i)
+			if named, ok := embedded.(*types.Named); ok && named.Obj().Exported() {
+				p["method "+name+"."+named.Obj().Name()] = symbol{kind: kindInterfaceMethod, owner: name, typ: embedded, text: text(embedded)}
+			}
+		}
+
+	default:
+		p["type "+name] = symbol{kind: kindType, typ: t, tparams: tparams, text: prefix + text(t)}
+	}
+
+	if named == nil {
+		return
+	}
+
+	for i := 0; i < named.NumMethods(); i++ {
+		method := named.Method(i)
+		if !method.Exported() {
+			continue
+		}
+
+		sig := method.Type().(*types.Signature)
+		_, pointer := sig.Recv().Type().(*types.Pointer)
+		p["method "+name+"."+method.Name()] = symbol{kind: kindMethod, owner: name, typ: sig, pointer: pointer, text: text(sig)}
+	}
+}
+
+// interfaceTypeSet is an interface with the embedded unions and approximations of a constraint only,
+// the methods and embedded interfaces are compared as methods of the interface
+func interfaceTypeSet(t *types.Interface) *types.Interface {
+	var embeddeds []types.Type
+	for i := 0; i < t.NumEmbeddeds(); i++ {
+		if embedded := t.EmbeddedType(i); !types.IsInterface(embedded) {
+			embeddeds = append(embeddeds, embedded)
+		}
+	}
+
+	return types.NewInterfaceType(nil, embeddeds).Complete()
+}
+
+// typeParamsText is the type parameter list of a generic type declaration followed by a space, empty otherwise
+func typeParamsText(tparams *types.TypeParamList, text func(types.Type) string) string {
+	if tparams.Len() == 0 {
+		return ""
+	}
+
+	var params []string
+	for i := 0; i < tparams.Len(); i++ {
+		param := tparams.At(i)
+		params = append(params, param.Obj().Name()+" "+text(param.Constraint()))
+	}
+
+	return "[" + strings.Join(params, ", ") + "] "
+}
+
+// identical is types.Identical for types of two type-checks of the sources: the named types of both are
+// distinct objects, so they correspond by package path and name. Parameter names are ignored.
+func identical(x, y types.Type) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+
+	// an alias like any is the type it denotes
+	x, y = types.Unalias(x), types.Unalias(y)
+	switch x := x.(type) {
+	case *types.Named:
+		y, ok := y.(*types.Named)
+		return ok && x.Obj().Name() == y.Obj().Name() && packagePath(x.Obj().Pkg()) == packagePath(y.Obj().Pkg()) &&
+			identicalTypeLists(x.TypeArgs(), y.TypeArgs())
+
+	case *types.TypeParam:
+		// the constraints are compared with the type parameter lists, comparing them here could recurse forever
+		y, ok := y.(*types.TypeParam)
+		return ok && x.Index() == y.Index()
+
+	case *types.Union:
+		y, ok := y.(*types.Union)
+		if !ok || x.Len() != y.Len() {
+			return false
+		}
+		for i := 0; i < x.Len(); i++ {
+			if x.Term(i).Tilde() != y.Term(i).Tilde() || !identical(x.Term(i).Type(), y.Term(i).Type()) {
+				return false
+			}
+		}
+		return true
+
+	case *types.Pointer:
+		y, ok := y.(*types.Pointer)
+		return ok && identical(x.Elem(), y.Elem())
+
+	case *types.Slice:
+		y, ok := y.(*types.Slice)
+		return ok && identical(x.Elem(), y.Elem())
+
+	case *types.Array:
+		y, ok := y.(*types.Array)
+		return ok && x.Len() == y.Len() && identical(x.Elem(), y.Elem())
+
+	case *types.Map:
+		y, ok := y.(*types.Map)
+		return ok && identical(x.Key(), y.Key()) && identical(x.Elem(), y.Elem())
+
+	case *types.Chan:
+		y, ok := y.(*types.Chan)
+		return ok && x.Dir() == y.Dir() && identical(x.Elem(), y.Elem())
+
+	case *types.Signature:
+		y, ok := y.(*types.Signature)
+		return ok && x.Variadic() == y.Variadic() &&
+			identicalTypeParams(x.TypeParams(), y.TypeParams()) &&
+			identicalTuples(x.Params(), y.Params()) && identicalTuples(x.Results(), y.Results())
+
+	case *types.Struct:
+		y, ok := y.(*types.Struct)
+		if !ok || x.NumFields() != y.NumFields() {
+			return false
+		}
+		for i := 0; i < x.NumFields(); i++ {
+			xf, yf := x.Field(i), y.Field(i)
+			if xf.Name() != yf.Name() || xf.Embedded() != yf.Embedded() || x.Tag(i) != y.Tag(i) || !identical(xf.Type(), yf.Type()) {
+				return false
+			}
+		}
+		return true
+
+	case *types.Interface:
+		y, ok := y.(*types.Interface)
+		if !ok || x.NumMethods() != y.NumMethods() {
+			return false
+		}
+		for i := 0; i < x.NumMethods(); i++ {
+			xm, ym := x.Method(i), y.Method(i)
+			if xm.Name() != ym.Name() || !identical(xm.Type(), ym.Type()) {
+				return false
+			}
+		}
+		// the type sets of constraints are embedded
+		if x.NumEmbeddeds() != y.NumEmbeddeds() {
+			return false
+		}
+		for i := 0; i < x.NumEmbeddeds(); i++ {
+			if !identical(x.EmbeddedType(i), y.EmbeddedType(i)) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return types.Identical(x, y)
+	}
+}
+
+func identicalTuples(x, y *types.Tuple) bool {
+	if x.Len() != y.Len() {
+		return false
+	}
+
+	for i := 0; i < x.Len(); i++ {
+		if !identical(x.At(i).Type(), y.At(i).Type()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// identicalTypeParams compares type parameters by their index and constraint, their names are ignored
+func identicalTypeParams(x, y *types.TypeParamList) bool {
+	if x.Len() != y.Len() {
+		return false
+	}
+
+	for i := 0; i < x.Len(); i++ {
+		if !identical(x.At(i).Constraint(), y.At(i).Constraint()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func identicalTypeLists(x, y *types.TypeList) bool {
+	if x.Len() != y.Len() {
+		return false
+	}
+
+	for i := 0; i < x.Len(); i++ {
+		if !identical(x.At(i), y.At(i)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func packagePath(pkg *types.Package) string {
+	if pkg == nil {
+		return ""
+	}
+
+	return pkg.Path()
+}
+
+// sourceImporter type-checks the packages of the module from the file set and stubs all other packages.
+// A stub package declares the names selected from it as named types, so the exported API referring to
+// other modules or the standard library still type-checks without their sources.
+type sourceImporter struct {
+	fset     *token.FileSet
+	module   string
+	dirs     map[string][]*ast.File
+	packages map[string]*types.Package
+	stubs    map[string]*stub
+}
+
+type stub struct {
+	name  string
+	names map[string]bool
+}
+
+func newSourceImporter(fset *token.FileSet, module string, dirs map[string][]*ast.File) *sourceImporter {
+	i := &sourceImporter{
+		fset:     fset,
+		module:   module,
+		dirs:     dirs,
+		packages: make(map[string]*types.Package),
+		stubs:    make(map[string]*stub),
+	}
+
+	for _, files := range dirs {
+		for _, file := range files {
+			i.collectSelections(file)
+		}
+	}
+
+	return i
+}
+
+func (i *sourceImporter) packagePath(dir string) string {
+	switch {
+	case i.module == "":
+		return dir
+	case dir == ".":
+		return i.module
+	default:
+		return i.module + "/" + dir
+	}
+}
+
+func (i *sourceImporter) packageDir(importPath string) (string, bool) {
+	dir := importPath
+	switch {
+	case i.module == "":
+	case importPath == i.module:
+		dir = "."
+	case strings.HasPrefix(importPath, i.module+"/"):
+		dir = strings.TrimPrefix(importPath, i.module+"/")
+	default:
+		return "", false
+	}
+
+	_, ok := i.dirs[dir]
+	return dir, ok
+}
+
+func (i *sourceImporter) Import(importPath string) (*types.Package, error) {
+	if pkg, ok := i.packages[importPath]; ok {
+		if pkg == nil {
+			return nil, fmt.Errorf("import cycle at %v", importPath)
+		}
+		return pkg, nil
+	}
+
+	dir, ok := i.packageDir(importPath)
+	if !ok {
+		pkg := i.stubPackage(importPath)
+		i.packages[importPath] = pkg
+		return pkg, nil
+	}
+
+	// marks the package as being checked to detect cycles
+	i.packages[importPath] = nil
+
+	conf := types.Config{
+		Importer:         i,
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		// type errors, eg of selections from stub packages, leave invalid types but do not stop the check
+		Error: func(error) {},
+	}
+	pkg, _ := conf.Check(importPath, i.fset, i.dirs[dir], nil)
+	i.packages[importPath] = pkg
+
+	return pkg, nil
+}
+
+func (i *sourceImporter) stubPackage(importPath string) *types.Package {
+	s, ok := i.stubs[importPath]
+	if !ok {
+		s = &stub{name: guessPackageName(importPath)}
+	}
+
+	pkg := types.NewPackage(importPath, s.name)
+	var names []string
+	for name := range s.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		obj := types.NewTypeName(token.NoPos, pkg, name, nil)
+		types.NewNamed(obj, types.NewInterfaceType(nil, nil).Complete(), nil)
+		pkg.Scope().Insert(obj)
+	}
+	pkg.MarkComplete()
+
+	return pkg
+}
+
+// collectSelections records the names selected from the imported packages of the file, eg "Version" of
+// "semver.Version", to declare them in the stub packages
+func (i *sourceImporter) collectSelections(file *ast.File) {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		s, ok := i.stubs[importPath]
+		if !ok {
+			s = &stub{name: guessPackageName(importPath), names: make(map[string]bool)}
+			i.stubs[importPath] = s
+		}
+
+		local := s.name
+		if spec.Name != nil {
+			local = spec.Name.Name
+		}
+		imports[local] = importPath
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		if ident, ok := selector.X.(*ast.Ident); ok && ident.Obj == nil {
+			if importPath, ok := imports[ident.Name]; ok {
+				i.stubs[importPath].names[selector.Sel.Name] = true
+			}
+		}
+
+		return true
+	})
+}
+
+var majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// guessPackageName derives the name of a package from its import path, eg "semver" for
+// "github.com/Masterminds/semver/v3" or "yaml" for "gopkg.in/yaml.v3"
+func guessPackageName(importPath string) string {
+	elements := strings.Split(importPath, "/")
+	name := elements[len(elements)-1]
+	if majorVersionSuffix.MatchString(name) && len(elements) > 1 {
+		name = elements[len(elements)-2]
+	}
+
+	if index := strings.Index(name, ".v"); index > 0 {
+		name = name[:index]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+
+	return strings.NewReplacer("-", "_", ".", "_").Replace(name)
+}
+
+func sortedNames(files map[string][]byte) []string {
+	var result []string
+	for name := range files {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,181 @@
+package apidiff
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"sort"
+)
+
+const Source = "go-api"
+
+type (
+	FileReader interface {
+		FilesAt(v *semver.Version, accept func(name string) bool) (map[string][]byte, error)
+	}
+
+	analyzer struct {
+		repo FileReader
+	}
+)
+
+func NewAnalyzer(repo FileReader) *analyzer {
+	return &analyzer{repo: repo}
+}
+
+func (a analyzer) Analyze(since *semver.Version) ([]Change, error) {
+	if since == nil {
+		return nil, nil
+	}
+
+	before, err := a.repo.FilesAt(since, isApiSource)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := a.repo.FilesAt(nil, isApiSource)
+	if err != nil {
+		return nil, err
+	}
+
+	return Diff(before, after)
+}
+
+// Diff compares the exported API of the Go packages in both file sets, type-checked with go/types.
+// Incompatible changes are major, compatible additions minor, and any other
+// change to the Go sources is patch level. The file sets may contain the go.mod
+// file to resolve the imports within the module.
+func Diff(before, after map[string][]byte) ([]Change, error) {
+	beforeApi, err := parseApi(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterApi, err := parseApi(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := compare(beforeApi, afterApi)
+	if len(changes) == 0 && sourcesDiffer(before, after) {
+		changes = append(changes, Change{
+			Source:      Source,
+			Level:       BumpLevelPatch,
+			Description: "Go sources changed without changes to the exported API",
+		})
+	}
+
+	return changes, nil
+}
+
+func compare(before, after api) []Change {
+	var result []Change
+	for _, dir := range sortedDirs(before, after) {
+		beforePkg, beforeOk := before[dir]
+		afterPkg, afterOk := after[dir]
+		switch {
+		case !afterOk:
+			result = append(result, change(BumpLevelMajor, "removed package %v", dir))
+		case !beforeOk:
+			result = append(result, change(BumpLevelMinor, "added package %v", dir))
+		default:
+			result = append(result, comparePackage(dir, beforePkg, afterPkg)...)
+		}
+	}
+
+	return result
+}
+
+func comparePackage(dir string, before, after packageApi) []Change {
+	var result []Change
+	for _, key := range sortedSymbols(before, after) {
+		beforeSym, beforeOk := before[key]
+		afterSym, afterOk := after[key]
+		switch {
+		case !afterOk:
+			result = append(result, change(BumpLevelMajor, "%v: removed %v", dir, key))
+
+		case !beforeOk && afterSym.kind == kindInterfaceMethod && before.has("type "+afterSym.owner):
+			result = append(result, change(BumpLevelMajor, "%v: added %v to an existing interface", dir, key))
+
+		case !beforeOk:
+			result = append(result, change(BumpLevelMinor, "%v: added %v", dir, key))
+
+		case beforeSym.decl != afterSym.decl || !identical(beforeSym.typ, afterSym.typ) || !identicalTypeParams(beforeSym.tparams, afterSym.tparams):
+			result = append(result, change(BumpLevelMajor, "%v: changed %v from %v to %v", dir, key, beforeSym.text, afterSym.text))
+
+		case afterSym.pointer && !beforeSym.pointer:
+			result = append(result, change(BumpLevelMajor, "%v: changed the receiver of %v to a pointer", dir, key))
+
+		case beforeSym.pointer && !afterSym.pointer:
+			result = append(result, change(BumpLevelMinor, "%v: changed the receiver of %v to a value", dir, key))
+		}
+	}
+
+	return result
+}
+
+func (p packageApi) has(key string) bool {
+	_, ok := p[key]
+	return ok
+}
+
+func change(lvl BumpLevel, format string, args ...interface{}) Change {
+	return Change{
+		Source:      Source,
+		Level:       lvl,
+		Description: fmt.Sprintf(format, args...),
+	}
+}
+
+// sourcesDiffer compares the Go sources only, a changed go.mod alone does not change the package
+func sourcesDiffer(before, after map[string][]byte) bool {
+	for name, content := range before {
+		if other, ok := after[name]; isGoSource(name) && (!ok || !bytes.Equal(content, other)) {
+			return true
+		}
+	}
+
+	for name := range after {
+		if _, ok := before[name]; isGoSource(name) && !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedDirs(before, after api) []string {
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	return sorted(keys)
+}
+
+func sortedSymbols(before, after packageApi) []string {
+	keys := make(map[string]bool)
+	for key := range before {
+		keys[key] = true
+	}
+	for key := range after {
+		keys[key] = true
+	}
+
+	return sorted(keys)
+}
+
+func sorted(keys map[string]bool) []string {
+	var result []string
+	for key := range keys {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,13 @@
+package apidiff_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestApidiff(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Apidiff Suite")
+}
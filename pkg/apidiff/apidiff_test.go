@@ -0,0 +1,266 @@
+package apidiff_test
+
+import (
+	"github.com/Masterminds/semver/v3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/pkg/apidiff"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	"github.com/timotto/semver-bumper/pkg/gitrepo"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	. "github.com/timotto/semver-bumper/pkg/test/git_testbed"
+	"os"
+	"strings"
+)
+
+const basePackage = `package lib
+
+type Client struct {
+	Name string
+	hidden int
+}
+
+type Store interface {
+	Get(key string) (string, error)
+}
+
+const Limit = 10
+
+func New(name string) *Client {
+	return &Client{Name: name}
+}
+
+func (c *Client) Do() error {
+	return nil
+}
+
+func helper() {}
+`
+
+var _ = Describe("Apidiff", func() {
+	Describe("Diff", func() {
+		DescribeTable(
+			"classifies the changes of the exported API",
+			func(after string, expectedLevel BumpLevel, expectedDescription string) {
+				changes, err := Diff(
+					map[string][]byte{"lib/lib.go": []byte(basePackage)},
+					map[string][]byte{"lib/lib.go": []byte(after)},
+				)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(changes).To(HaveLen(1))
+				Expect(changes[0].Source).To(Equal(Source))
+				Expect(changes[0].Level).To(Equal(expectedLevel))
+				Expect(changes[0].Description).To(ContainSubstring(expectedDescription))
+			},
+			Entry("removed func", replace("func New(name string) *Client {\n\treturn &Client{Name: name}\n}", ""),
+				BumpLevelMajor, "removed func New"),
+			Entry("changed func signature", replace("func New(name string)", "func New(name string, age int)"),
+				BumpLevelMajor, "changed func New"),
+			Entry("removed method", replace("func (c *Client) Do() error {\n\treturn nil\n}", ""),
+				BumpLevelMajor, "removed method Client.Do"),
+			Entry("removed struct field", replace("\tName string\n", ""),
+				BumpLevelMajor, "removed field Client.Name"),
+			Entry("changed const type", replace("const Limit = 10", "const Limit int64 = 10"),
+				BumpLevelMajor, "changed const Limit"),
+			Entry("added method to an interface", replace("\tGet(key string) (string, error)\n", "\tGet(key string) (string, error)\n\tPut(key, value string) error\n"),
+				BumpLevelMajor, "added method Store.Put"),
+			Entry("added func", basePackage+"\nfunc Other() {}\n",
+				BumpLevelMinor, "added func Other"),
+			Entry("added struct field", replace("\thidden int\n", "\thidden int\n\tAge int\n"),
+				BumpLevelMinor, "added field Client.Age"),
+			Entry("added type", basePackage+"\ntype Other int\n",
+				BumpLevelMinor, "added type Other"),
+			Entry("changed method receiver to a value", replace("func (c *Client) Do() error", "func (c Client) Do() error"),
+				BumpLevelMinor, "changed the receiver of method Client.Do to a value"),
+			Entry("renamed func parameter", replace("func New(name string) *Client {\n\treturn &Client{Name: name}", "func New(n string) *Client {\n\treturn &Client{Name: n}"),
+				BumpLevelPatch, "without changes"),
+			Entry("renamed interface method parameter", replace("Get(key string)", "Get(k string)"),
+				BumpLevelPatch, "without changes"),
+			Entry("changed struct field to an identical type", replace("\tName string\n", "\tName Label\n")+"\ntype Label = string\n",
+				BumpLevelMinor, "added type Label"),
+			Entry("changed implementation", replace("return nil\n}\n\nfunc helper", "return errNothing\n}\n\nfunc helper"),
+				BumpLevelPatch, "without changes"),
+			Entry("changed unexported symbols", replace("func helper() {}", "func helper(a int) {}"),
+				BumpLevelPatch, "without changes"),
+		)
+
+		It("reports a value receiver changed to a pointer", func() {
+			before := replace("func (c *Client) Do() error", "func (c Client) Do() error")
+			changes, err := Diff(
+				map[string][]byte{"lib/lib.go": []byte(before)},
+				map[string][]byte{"lib/lib.go": []byte(basePackage)},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes).To(Equal([]Change{
+				{Source: Source, Level: BumpLevelMajor, Description: "lib: changed the receiver of method Client.Do to a pointer"},
+			}))
+		})
+
+		Describe("types of other packages", func() {
+			const goMod = "module example.com/m\n\ngo 1.17\n"
+			const model = "package model\n\ntype Version struct{}\n\ntype Range struct{}\n"
+			var lib = func(param string) string {
+				return "package lib\n\nimport (\n\t\"example.com/m/model\"\n\t\"github.com/Masterminds/semver/v3\"\n)\n\n" +
+					"func Parse(s string) (" + param + ", error) {\n\treturn nil, nil\n}\n\n" +
+					"func Check(c *semver.Constraints) bool {\n\treturn c != nil\n}\n"
+			}
+			var files = func(param string) map[string][]byte {
+				return map[string][]byte{"go.mod": []byte(goMod), "model/model.go": []byte(model), "lib/lib.go": []byte(lib(param))}
+			}
+
+			It("reports nothing when they are unchanged", func() {
+				changes, err := Diff(files("*model.Version"), files("*model.Version"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(changes).To(BeEmpty())
+			})
+
+			It("reports a changed type of the module", func() {
+				changes, err := Diff(files("*model.Version"), files("*model.Range"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(changes).To(Equal([]Change{
+					{Source: Source, Level: BumpLevelMajor, Description: "lib: changed func Parse from func(s string) (*model.Version, error) to func(s string) (*model.Range, error)"},
+				}))
+			})
+
+			It("reports a changed type of another module", func() {
+				changes, err := Diff(files("*semver.Version"), files("*semver.Constraints"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(changes).To(HaveLen(1))
+				Expect(changes[0].Level).To(Equal(BumpLevelMajor))
+				Expect(changes[0].Description).To(ContainSubstring("changed func Parse"))
+			})
+		})
+
+		Describe("generics", func() {
+			const generic = `package lib
+
+type Number interface {
+	~int | ~float64
+}
+
+type Box[T any] struct {
+	V T
+}
+
+func (b Box[T]) Get() T {
+	return b.V
+}
+
+func Map[T any, R any](values []T, fn func(T) R) []R {
+	return nil
+}
+
+func Sum[N Number](values ...N) N {
+	var sum N
+	return sum
+}
+`
+			DescribeTable(
+				"compares type parameters by their position and constraint",
+				func(after string, expectedLevel BumpLevel, expectedDescription string) {
+					changes, err := Diff(
+						map[string][]byte{"lib/lib.go": []byte(generic)},
+						map[string][]byte{"lib/lib.go": []byte(after)},
+					)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(changes).To(HaveLen(1))
+					Expect(changes[0].Level).To(Equal(expectedLevel))
+					Expect(changes[0].Description).To(ContainSubstring(expectedDescription))
+				},
+				Entry("changed implementation", strings.Replace(generic, "return nil", "return make([]R, len(values))", 1),
+					BumpLevelPatch, "without changes"),
+				Entry("renamed type parameters", strings.NewReplacer("[T any, R any](values []T, fn func(T) R) []R", "[In any, Out any](values []In, fn func(In) Out) []Out", "Box[T]) Get() T", "Box[E]) Get() E").Replace(generic),
+					BumpLevelPatch, "without changes"),
+				Entry("changed constraint of a func", strings.Replace(generic, "func Sum[N Number]", "func Sum[N ~int]", 1),
+					BumpLevelMajor, "changed func Sum"),
+				Entry("changed type set of a constraint", strings.Replace(generic, "~int | ~float64", "~int", 1),
+					BumpLevelMajor, "changed type Number"),
+				Entry("changed constraint of a type", strings.Replace(generic, "type Box[T any]", "type Box[T comparable]", 1),
+					BumpLevelMajor, "changed type Box from [T any] struct to [T comparable] struct"),
+				Entry("swapped type parameters", strings.Replace(generic, "func(T) R) []R", "func(T) R) []T", 1),
+					BumpLevelMajor, "changed func Map"),
+			)
+		})
+
+		It("reports removed and added packages", func() {
+			changes, err := Diff(
+				map[string][]byte{"a/a.go": []byte("package a\n")},
+				map[string][]byte{"b/b.go": []byte("package b\n")},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes).To(Equal([]Change{
+				{Source: Source, Level: BumpLevelMajor, Description: "removed package a"},
+				{Source: Source, Level: BumpLevelMinor, Description: "added package b"},
+			}))
+		})
+
+		It("ignores main packages", func() {
+			changes, err := Diff(
+				map[string][]byte{"cmd/main.go": []byte("package main\n\nfunc Exported() {}\n")},
+				map[string][]byte{"cmd/main.go": []byte("package main\n")},
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes).To(HaveLen(1))
+			Expect(changes[0].Level).To(Equal(BumpLevelPatch))
+		})
+
+		It("returns nothing when the sources are unchanged", func() {
+			files := map[string][]byte{"lib/lib.go": []byte(basePackage)}
+			changes, err := Diff(files, files)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes).To(BeEmpty())
+		})
+
+		It("returns an error when a file cannot be parsed", func() {
+			_, err := Diff(nil, map[string][]byte{"lib/lib.go": []byte("not go")})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("lib/lib.go"))
+		})
+	})
+
+	Describe("Analyze", func() {
+		var bed *TestbedRepo
+		BeforeEach(CreateBeforeEach(os.TempDir(), &bed))
+		AfterEach(TeardownAfterEach(&bed))
+
+		var analyze = func(since *semver.Version) []Change {
+			cfg := &Options{}
+			Expect(cfg.Valid()).ToNot(HaveOccurred())
+			repo, err := gitrepo.NewGitRepo(cfg, bed.Path())
+			Expect(err).ToNot(HaveOccurred())
+
+			changes, err := NewAnalyzer(repo).Analyze(since)
+			Expect(err).ToNot(HaveOccurred())
+
+			return changes
+		}
+
+		BeforeEach(func() {
+			bed.
+				AddCommitWithContent("one", map[string]string{"lib/lib.go": basePackage}).
+				AddLightweightTag("1.0.0")
+		})
+
+		It("compares the Go sources of the tagged commit with HEAD", func() {
+			bed.AddCommitWithContent("two", map[string]string{
+				"lib/lib.go":               replace("func (c *Client) Do() error {\n\treturn nil\n}", ""),
+				"lib/lib_test.go":          "package lib\n\nfunc TestIgnored() {}\n",
+				"lib/internal/internal.go": "package internal\n\nfunc Ignored() {}\n",
+			})
+
+			Expect(analyze(semver.MustParse("1.0.0"))).To(Equal([]Change{
+				{Source: Source, Level: BumpLevelMajor, Description: "lib: removed method Client.Do"},
+			}))
+		})
+
+		It("returns nothing without a release to compare with", func() {
+			Expect(analyze(nil)).To(BeEmpty())
+		})
+	})
+})
+
+func replace(old, new string) string {
+	Expect(basePackage).To(ContainSubstring(old))
+	return strings.Replace(basePackage, old, new, 1)
+}
@@ -1,90 +1,355 @@
 package bumper
 
 import (
+	"errors"
 	"fmt"
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/timotto/semver-bumper/pkg/logger"
 	. "github.com/timotto/semver-bumper/pkg/model"
+	"github.com/timotto/semver-bumper/pkg/scheme"
+	"strings"
+	"time"
 )
 
 type (
 	Config interface {
 		BumpPrerelease() bool
 		ShouldFakePrerelease() (string, bool)
+		ShouldReleaseAs() (*semver.Version, bool)
+		ShouldMatchConstraint() (*semver.Constraints, bool)
+		AllowMajorBump() bool
+		FailOnUnderstatedLevel() bool
+		FailOnNoMatch() bool
 		InitialVersionValue() *semver.Version
+		SchemeValue() scheme.Scheme
+		Now() time.Time
+		LoggerValue() *logger.Logger
 	}
 
 	GitRepo interface {
 		LatestTaggedRelease() (*semver.Version, error)
 		LatestTaggedPrerelease() (*semver.Version, error)
 		CommitMessagesSince(v *semver.Version) ([]*object.Commit, error)
+		LevelLimits(commit *object.Commit) (LevelLimits, error)
+	}
+
+	levelLimiter interface {
+		LevelLimits(commit *object.Commit) (LevelLimits, error)
 	}
 
 	Estimator interface {
-		BumpLevelFrom(commitMessages []string) BumpLevel
+		FallbackLevel() BumpLevel
+		CommitBumpLevel(commitMessage string) BumpLevel
 		NextPrerelease(pre string) (string, error)
 	}
+
+	Analyzer interface {
+		Analyze(since *semver.Version) ([]Change, error)
+	}
+
+	// Result is the next version with the previous versions and the reasons for the bump
+	Result struct {
+		Version *semver.Version
+		// LatestRelease is the latest tagged release, nil if there is none
+		LatestRelease *semver.Version
+		// LatestPrerelease is the latest tagged version including prereleases, nil if there is none
+		LatestPrerelease *semver.Version
+		// Level is the bump level of the next release, none without a latest release or with release-as
+		Level BumpLevel
+		// Commits are the commits since the latest release
+		Commits []*object.Commit
+		// Reasons are the level of each commit followed by the changes detected by the analyzers
+		Reasons []Reason
+	}
+
+	// Reason is the level of a commit or a change detected by an analyzer
+	Reason struct {
+		Change
+		// Commit is nil for changes detected by an analyzer
+		Commit *object.Commit
+	}
+
+	// NoMatchError lists the commits matching no keyword when FailOnNoMatch is true
+	NoMatchError struct {
+		Commits []*object.Commit
+	}
+
+	// MajorBumpError lists the commits and changes justifying a major bump when AllowMajorBump is false
+	MajorBumpError struct {
+		Reasons []Reason
+	}
 )
 
-func Bump(conf Config, repo GitRepo, esti Estimator) (*semver.Version, []*object.Commit, error) {
-	nextRelease, commits, err := bumpRelease(repo, esti)
-	if err != nil {
+// commitSource is the source of the reasons of commits
+const commitSource = "commit"
+
+// ErrNothingToRelease is returned along with the latest release, or the latest prerelease when bumping
+// a prerelease, if nothing justifies a new release
+var ErrNothingToRelease = errors.New("nothing to release")
+
+func (e NoMatchError) Error() string {
+	lines := []string{fmt.Sprintf("%v commits match no keyword:", len(e.Commits))}
+	for _, commit := range e.Commits {
+		lines = append(lines, fmt.Sprintf("\t%v %v", commit.Hash.String()[:7], subject(commit.Message)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (e MajorBumpError) Error() string {
+	lines := []string{"a major version bump is not allowed, it is justified by:"}
+	for _, reason := range e.Reasons {
+		if reason.Commit == nil {
+			lines = append(lines, fmt.Sprintf("\t%v", reason.Change))
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("\t%v %v", reason.Commit.Hash.String()[:7], reason.Description))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Bump returns the next version and the commits since the latest release.
+// If nothing justifies a new release, the latest release is returned with ErrNothingToRelease.
+func Bump(conf Config, repo GitRepo, esti Estimator, analyzers ...Analyzer) (*semver.Version, []*object.Commit, error) {
+	result, err := Compute(conf, repo, esti, analyzers...)
+	if result == nil {
 		return nil, nil, err
 	}
 
+	return result.Version, result.Commits, err
+}
+
+// Compute returns the next version with the previous versions and the reasons for the bump.
+// If nothing justifies a new release, the result has the latest release and ErrNothingToRelease is returned with it.
+// The next version must match the constraint of the configuration.
+// The logger of the configuration receives the latest versions, the bump level, and the next version.
+func Compute(conf Config, repo GitRepo, esti Estimator, analyzers ...Analyzer) (*Result, error) {
+	result, err := compute(conf, repo, esti, analyzers...)
+	if result != nil {
+		logResult(conf, conf.LoggerValue(), result)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if err := matchConstraint(conf, result.Version); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// matchConstraint fails if the version does not match the constraint of the configuration
+func matchConstraint(conf Config, v *semver.Version) error {
+	if constraint, ok := conf.ShouldMatchConstraint(); ok && !matches(constraint, v) {
+		return fmt.Errorf("version %v does not match the constraint %v", conf.SchemeValue().String(v), constraint)
+	}
+
+	return nil
+}
+
+func logResult(conf Config, log *logger.Logger, result *Result) {
+	s := conf.SchemeValue()
+	log.Info("latest release", "version", versionString(s, result.LatestRelease))
+	log.Info("latest prerelease", "version", versionString(s, result.LatestPrerelease))
+	if log.Enabled(logger.LevelDebug) {
+		for _, reason := range result.Reasons {
+			if reason.Commit != nil {
+				log.Debug("commit level", "commit", reason.Commit.Hash, "bump", reason.Level)
+			} else {
+				log.Debug("change level", "source", reason.Source, "bump", reason.Level, "change", reason.Description)
+			}
+		}
+	}
+	log.Info("next version", "version", versionString(s, result.Version), "bump", result.Level)
+}
+
+// versionString is empty for no version
+func versionString(s scheme.Scheme, v *semver.Version) string {
+	if v == nil {
+		return ""
+	}
+
+	return s.String(v)
+}
+
+func compute(conf Config, repo GitRepo, esti Estimator, analyzers ...Analyzer) (*Result, error) {
+	result := &Result{}
+	var err error
+	if result.LatestRelease, err = repo.LatestTaggedRelease(); err != nil {
+		return nil, err
+	}
+
+	if result.LatestPrerelease, err = repo.LatestTaggedPrerelease(); err != nil {
+		return nil, err
+	}
+
+	if result.Commits, err = repo.CommitMessagesSince(result.LatestRelease); err != nil {
+		return nil, err
+	}
+
+	if result.Reasons, err = commitReasons(repo, esti, result.Commits); err != nil {
+		return nil, err
+	}
+
+	if releaseAs, ok := conf.ShouldReleaseAs(); ok {
+		return computeReleaseAs(conf, result, releaseAs)
+	}
+
+	now := conf.Now()
+	if err := computeRelease(conf, esti, analyzers, result, now); err != nil {
+		return nil, err
+	}
+
 	if !conf.BumpPrerelease() {
-		if nextRelease == nil {
-			return conf.InitialVersionValue(), commits, nil
+		if result.LatestRelease == nil {
+			result.Version = initialVersion(conf, now)
+			return result, nil
+		}
+
+		if result.Version.Equal(result.LatestRelease) {
+			return result, ErrNothingToRelease
 		}
 
-		return nextRelease, commits, nil
+		return result, nil
 	}
 
 	latestPrerelease, ok, err := fakePrerelease(conf)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if !ok {
-		latestPrerelease, err = repo.LatestTaggedPrerelease()
-		if err != nil {
-			return nil, nil, err
-		}
+		latestPrerelease = result.LatestPrerelease
+	}
+
+	// like a release, a prerelease needs a commit justifying a new release since the latest release
+	if result.LatestRelease != nil && result.Version.Equal(result.LatestRelease) {
+		result.Version = latestPrerelease
+		return result, ErrNothingToRelease
 	}
 
-	if latestPrerelease == nil {
+	nextRelease := result.Version
+	switch {
+	case latestPrerelease == nil:
 		if nextRelease == nil {
-			nextRelease = conf.InitialVersionValue()
+			nextRelease = initialVersion(conf, now)
 		}
+		result.Version, err = prerelease1(esti, nextRelease)
 
-		return prerelease1(esti, commits, nextRelease)
-	}
+	case nextReleaseIsGreaterThanLastPrerelease(conf.SchemeValue(), nextRelease, latestPrerelease):
+		result.Version, err = prerelease1(esti, nextRelease)
 
-	if nextReleaseIsGreaterThanLastPrerelease(nextRelease, latestPrerelease) {
-		return prerelease1(esti, commits, nextRelease)
+	default:
+		result.Version, err = bumpPrerelease(esti, latestPrerelease)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return bumpPrerelease(esti, latestPrerelease, commits)
+	return result, nil
 }
 
-func bumpRelease(repo GitRepo, esti Estimator) (*semver.Version, []*object.Commit, error) {
-	latestRelease, err := repo.LatestTaggedRelease()
-	if err != nil {
-		return nil, nil, err
+// computeRelease sets the level and the next release at the given time, there is no next release without a latest release
+func computeRelease(conf Config, esti Estimator, analyzers []Analyzer, result *Result, now time.Time) error {
+	if result.LatestRelease == nil {
+		return nil
+	}
+
+	if conf.FailOnNoMatch() {
+		if err := noMatch(esti, result.Commits); err != nil {
+			return err
+		}
 	}
 
-	commits, err := repo.CommitMessagesSince(latestRelease)
+	lvl := esti.FallbackLevel()
+	for _, reason := range result.Reasons {
+		lvl = lvl.Max(reason.Level)
+	}
+
+	changes, err := analyze(conf, analyzers, result.LatestRelease, lvl)
 	if err != nil {
-		return nil, nil, err
+		return err
+	}
+
+	for _, change := range changes {
+		result.Reasons = append(result.Reasons, Reason{Change: change})
+		lvl = lvl.Max(change.Level)
+	}
+
+	if lvl == BumpLevelMajor && !conf.AllowMajorBump() {
+		return MajorBumpError{Reasons: majorReasons(result.Reasons)}
+	}
+
+	result.Version = conf.SchemeValue().Next(result.LatestRelease, lvl, now)
+	result.Level = lvl
+
+	return nil
+}
+
+func majorReasons(reasons []Reason) []Reason {
+	var result []Reason
+	for _, reason := range reasons {
+		if reason.Level == BumpLevelMajor {
+			result = append(result, reason)
+		}
 	}
 
-	if latestRelease == nil {
-		return nil, commits, err
+	return result
+}
+
+// matches checks a prerelease like its release, so that "<3.0.0" rejects "3.0.0-rc.1" but accepts "2.1.0-rc.1"
+func matches(constraint *semver.Constraints, v *semver.Version) bool {
+	release, _ := v.SetPrerelease("")
+
+	return constraint.Check(&release)
+}
+
+func noMatch(esti Estimator, commits []*object.Commit) error {
+	var unmatched []*object.Commit
+	for _, commit := range commits {
+		if esti.CommitBumpLevel(commit.Message) == BumpLevelNone {
+			unmatched = append(unmatched, commit)
+		}
 	}
 
-	nextRelease := bump(latestRelease, esti.BumpLevelFrom(messagesFrom(commits)))
+	if len(unmatched) > 0 {
+		return NoMatchError{Commits: unmatched}
+	}
 
-	return &nextRelease, commits, nil
+	return nil
+}
+
+func computeReleaseAs(conf Config, result *Result, releaseAs *semver.Version) (*Result, error) {
+	if result.LatestRelease != nil && !releaseAs.GreaterThan(result.LatestRelease) {
+		return nil, fmt.Errorf("release-as version %v must be greater than the latest release %v", releaseAs, result.LatestRelease)
+	}
+
+	if conf.BumpPrerelease() && result.LatestPrerelease != nil && !releaseAs.GreaterThan(result.LatestPrerelease) {
+		return nil, fmt.Errorf("release-as version %v must be greater than the latest prerelease %v", releaseAs, result.LatestPrerelease)
+	}
+
+	result.Version = releaseAs
+	result.Level = changedLevel(result.LatestRelease, releaseAs)
+
+	return result, nil
+}
+
+// changedLevel is the level of the highest segment changed since the latest release, none without a release
+func changedLevel(latest, next *semver.Version) BumpLevel {
+	switch {
+	case latest == nil:
+		return BumpLevelNone
+	case next.Major() != latest.Major():
+		return BumpLevelMajor
+	case next.Minor() != latest.Minor():
+		return BumpLevelMinor
+	default:
+		return BumpLevelPatch
+	}
 }
 
 func fakePrerelease(conf Config) (*semver.Version, bool, error) {
@@ -93,7 +358,7 @@ func fakePrerelease(conf Config) (*semver.Version, bool, error) {
 		return nil, false, nil
 	}
 
-	version, err := semver.StrictNewVersion(prerelease)
+	version, err := conf.SchemeValue().Parse(prerelease)
 	if err != nil {
 		return nil, true, fmt.Errorf("cannot parse given prerelease version: %w", err)
 	}
@@ -101,62 +366,103 @@ func fakePrerelease(conf Config) (*semver.Version, bool, error) {
 	return version, true, nil
 }
 
-func prerelease1(esti Estimator, commits []*object.Commit, v *semver.Version) (*semver.Version, []*object.Commit, error) {
-	if nextPrerelease, err := esti.NextPrerelease(""); err != nil {
-		return nil, nil, err
-	} else if ver, err := v.SetPrerelease(nextPrerelease); err != nil {
-		return nil, nil, err
-	} else {
-		return &ver, commits, nil
+func prerelease1(esti Estimator, v *semver.Version) (*semver.Version, error) {
+	nextPrerelease, err := esti.NextPrerelease("")
+	if err != nil {
+		return nil, err
+	}
+
+	ver, err := v.SetPrerelease(nextPrerelease)
+	if err != nil {
+		return nil, err
 	}
+
+	return &ver, nil
 }
 
-func bumpPrerelease(esti Estimator, latestPrerelease *semver.Version, commits []*object.Commit) (*semver.Version, []*object.Commit, error) {
+func bumpPrerelease(esti Estimator, latestPrerelease *semver.Version) (*semver.Version, error) {
 	pre, err := esti.NextPrerelease(latestPrerelease.Prerelease())
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	nextPrerelease, err := latestPrerelease.SetPrerelease(pre)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	return &nextPrerelease, commits, nil
+	return &nextPrerelease, nil
 }
 
-func bump(v *semver.Version, lvl BumpLevel) semver.Version {
-	switch lvl {
-	case BumpLevelMajor:
-		return v.IncMajor()
+// initialVersion is the configured initial version, or the initial version of the scheme at the given time
+func initialVersion(conf Config, at time.Time) *semver.Version {
+	if v := conf.InitialVersionValue(); v != nil {
+		return v
+	}
 
-	case BumpLevelMinor:
-		return v.IncMinor()
+	return conf.SchemeValue().Next(nil, BumpLevelNone, at)
+}
 
-	case BumpLevelPatch:
-		return v.IncPatch()
+// commitReasons returns the level of each commit within the level limits of its files
+func commitReasons(repo levelLimiter, esti Estimator, commits []*object.Commit) ([]Reason, error) {
+	var result []Reason
+	for _, commit := range commits {
+		limits, err := repo.LevelLimits(commit)
+		if err != nil {
+			return nil, err
+		}
 
-	default:
-		return *v
+		result = append(result, Reason{
+			Change: Change{
+				Source:      commitSource,
+				Level:       limits.Apply(esti.CommitBumpLevel(commit.Message)),
+				Description: subject(commit.Message),
+			},
+			Commit: commit,
+		})
 	}
+
+	return result, nil
 }
 
-func messagesFrom(commits []*object.Commit) []string {
-	var result []string
+// analyze returns the changes detected by all analyzers
+func analyze(conf Config, analyzers []Analyzer, latestRelease *semver.Version, messagesLvl BumpLevel) ([]Change, error) {
+	lvl := BumpLevelNone
+	var result []Change
+	var understated []string
+	for _, analyzer := range analyzers {
+		changes, err := analyzer.Analyze(latestRelease)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, changes...)
+		for _, change := range changes {
+			lvl = lvl.Max(change.Level)
+			if change.Level > messagesLvl {
+				understated = append(understated, fmt.Sprintf("\t%v %v", change.Level, change))
+			}
+		}
+	}
 
-	for _, commit := range commits {
-		result = append(result, commit.Message)
+	if conf.FailOnUnderstatedLevel() && len(understated) > 0 {
+		return nil, fmt.Errorf("the commit messages justify a %v bump but the detected changes require a %v bump:\n%v",
+			messagesLvl, lvl, strings.Join(understated, "\n"))
 	}
 
-	return result
+	return result, nil
 }
 
-func nextReleaseIsGreaterThanLastPrerelease(nextRelease, lastPrerelease *semver.Version) bool {
+func nextReleaseIsGreaterThanLastPrerelease(s scheme.Scheme, nextRelease, lastPrerelease *semver.Version) bool {
 	if nextRelease == nil {
 		return false
 	}
 
 	lastPrereleaseReleased, _ := lastPrerelease.SetPrerelease("")
 
-	return nextRelease.GreaterThan(&lastPrereleaseReleased)
+	return s.Compare(nextRelease, &lastPrereleaseReleased) > 0
+}
+
+func subject(message string) string {
+	return strings.SplitN(strings.TrimSpace(message), "\n", 2)[0]
 }
@@ -1,17 +1,23 @@
 package bumper_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	. "github.com/timotto/semver-bumper/pkg/bumper"
 	. "github.com/timotto/semver-bumper/pkg/config"
 	"github.com/timotto/semver-bumper/pkg/estimator"
 	"github.com/timotto/semver-bumper/pkg/gitrepo"
+	"github.com/timotto/semver-bumper/pkg/logger"
+	. "github.com/timotto/semver-bumper/pkg/model"
 	. "github.com/timotto/semver-bumper/pkg/test/git_testbed"
 	"os"
+	"time"
 )
 
 const (
@@ -132,6 +138,38 @@ var _ = Describe("Bumper", func() {
 						It("bumps the major level", expectBump(majorBump, majorLevelCommitMessage, minorLevelCommitMessage))
 					})
 				})
+
+				When("no commit matches a keyword", func() {
+					BeforeEach(bedWith(commits("three")))
+					It("returns the existing release with ErrNothingToRelease", func() {
+						actualResult, actualCommits, err := Bump(cfg, repo, esti)
+						Expect(err).To(MatchError(ErrNothingToRelease))
+						Expect(actualResult.String()).To(Equal(existingReleaseVersion))
+						Expect(messagesFrom(actualCommits...)).To(ConsistOf("three"))
+					})
+				})
+
+				When("FailOnNoMatch==true", func() {
+					BeforeEach(func() {
+						cfg.FailNoMatch = true
+					})
+
+					When("a commit matches no keyword", func() {
+						BeforeEach(bedWith(commits(minorLevelCommitMessage, "three")))
+						It("returns an error listing the commit", func() {
+							_, _, err := Bump(cfg, repo, esti)
+							var noMatchErr NoMatchError
+							Expect(errors.As(err, &noMatchErr)).To(BeTrue())
+							Expect(messagesFrom(noMatchErr.Commits...)).To(ConsistOf("three"))
+							Expect(err.Error()).To(ContainSubstring("1 commits match no keyword"))
+						})
+					})
+
+					When("all commits match a keyword", func() {
+						BeforeEach(bedWith(commits(minorLevelCommitMessage)))
+						It("bumps the level", expectBump(minorBump, minorLevelCommitMessage))
+					})
+				})
 			})
 
 			When("there are no tags", func() {
@@ -208,6 +246,32 @@ var _ = Describe("Bumper", func() {
 			It("returns the existing prerelease + 1",
 				expectVersion("1.1.1-almost.2"))
 		})
+		When("no commit since the release matches a keyword", func() {
+			BeforeEach(func() {
+				bed.
+					AddCommits("one").
+					AddLightweightTag("1.1.0").
+					AddCommit("two")
+			})
+			It("returns the existing release with ErrNothingToRelease", func() {
+				actualResult, _, err := Bump(cfg, repo, esti)
+				Expect(err).To(MatchError(ErrNothingToRelease))
+				Expect(actualResult.String()).To(Equal("1.1.0"))
+			})
+
+			When("there is a prerelease", func() {
+				BeforeEach(func() {
+					bed.
+						AddLightweightTag("1.1.1-almost.1").
+						AddCommit("three")
+				})
+				It("returns the existing prerelease with ErrNothingToRelease", func() {
+					actualResult, _, err := Bump(cfg, repo, esti)
+					Expect(err).To(MatchError(ErrNothingToRelease))
+					Expect(actualResult.String()).To(Equal("1.1.1-almost.1"))
+				})
+			})
+		})
 		When("FakePrerelease is set", func() {
 			BeforeEach(func() {
 				cfg.FakePrerelease = "11.5.9-almost.11"
@@ -216,10 +280,322 @@ var _ = Describe("Bumper", func() {
 				expectVersion("11.5.9-almost.12"))
 		})
 	})
+
+	When("there are path level rules", func() {
+		BeforeEach(func() {
+			repo = aGitRepo(bed, func(o *Options) {
+				o.PathMax = map[string]string{"docs": "patch"}
+				o.PathMin = map[string]string{"api": "minor"}
+			})
+			bed.
+				AddCommitAt("main.go", "one").
+				AddLightweightTag("1.0.0")
+		})
+
+		When("a commit changes only files matching a path max rule", func() {
+			BeforeEach(func() {
+				bed.AddCommitAt("docs/readme.md", majorLevelCommitMessage)
+			})
+			It("caps the level of that commit", expectVersion("1.0.1"))
+
+			When("another commit justifies a higher level", func() {
+				BeforeEach(func() {
+					bed.AddCommitAt("feature.go", minorLevelCommitMessage)
+				})
+				It("bumps that level", expectVersion("1.1.0"))
+			})
+		})
+
+		When("a commit changes a file matching a path min rule", func() {
+			BeforeEach(func() {
+				bed.AddCommitAt("api/service.proto", "no keyword")
+			})
+			It("raises the level of that commit", expectVersion("1.1.0"))
+		})
+	})
+
+	When("there are analyzers", func() {
+		var analyzer *fakeAnalyzer
+		var expectVersionWithAnalyzer = func(expectedVersion string) func() {
+			return func() {
+				actualResult, _, err := Bump(cfg, repo, esti, analyzer)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actualResult.String()).To(Equal(expectedVersion))
+			}
+		}
+		BeforeEach(func() {
+			analyzer = &fakeAnalyzer{}
+			bed.
+				AddCommits("one").
+				AddLightweightTag("1.0.0").
+				AddCommits(patchLevelCommitMessage)
+		})
+
+		It("analyzes the changes since the latest release", func() {
+			_, _, err := Bump(cfg, repo, esti, analyzer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(analyzer.since).To(Equal(semver.MustParse("1.0.0")))
+		})
+
+		When("the detected changes justify a higher level than the commit messages", func() {
+			BeforeEach(func() {
+				analyzer.changes = []Change{{Source: "fake", Level: BumpLevelMajor, Description: "expected change"}}
+			})
+			It("bumps the level of the detected changes", expectVersionWithAnalyzer("2.0.0"))
+
+			When("FailOnUnderstatedLevel==true", func() {
+				BeforeEach(func() {
+					cfg.FailUnderstated = true
+				})
+				It("returns an error listing the changes", func() {
+					_, _, err := Bump(cfg, repo, esti, analyzer)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("expected change"))
+				})
+			})
+		})
+
+		When("the detected changes justify a lower level than the commit messages", func() {
+			BeforeEach(func() {
+				cfg.FailUnderstated = true
+				analyzer.changes = []Change{{Source: "fake", Level: BumpLevelNone, Description: "expected change"}}
+			})
+			It("bumps the level of the commit messages", expectVersionWithAnalyzer("1.0.1"))
+		})
+
+		When("the analyzer fails", func() {
+			BeforeEach(func() {
+				analyzer.err = fmt.Errorf("expected error")
+			})
+			It("returns the error", func() {
+				_, _, err := Bump(cfg, repo, esti, analyzer)
+				Expect(err).To(MatchError("expected error"))
+			})
+		})
+	})
+
+	Describe("Compute", func() {
+		BeforeEach(bedWith(
+			commits("one"),
+			lightweightTags("1.0.0"),
+			commits("two"),
+			lightweightTags(asPrerelease1("1.0.1")),
+			commits(minorLevelCommitMessage),
+		))
+
+		It("returns the previous versions, the level, the commits, and the reasons", func() {
+			result, err := Compute(cfg, repo, esti, &fakeAnalyzer{changes: []Change{{Source: "fake", Level: BumpLevelPatch, Description: "expected change"}}})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.Version.String()).To(Equal("1.1.0"))
+			Expect(result.LatestRelease.String()).To(Equal("1.0.0"))
+			Expect(result.LatestPrerelease.String()).To(Equal(asPrerelease1("1.0.1")))
+			Expect(result.Level).To(Equal(BumpLevelMinor))
+			Expect(messagesFrom(result.Commits...)).To(ConsistOf(minorLevelCommitMessage, "two"))
+
+			Expect(result.Reasons).To(HaveLen(3))
+			Expect(result.Reasons[0].Commit.Message).To(Equal(minorLevelCommitMessage))
+			Expect(result.Reasons[0].Level).To(Equal(BumpLevelMinor))
+			Expect(result.Reasons[1].Level).To(Equal(BumpLevelNone))
+			Expect(result.Reasons[2].Commit).To(BeNil())
+			Expect(result.Reasons[2].String()).To(Equal("fake: expected change"))
+		})
+
+		It("logs the next version to the logger of the configuration", func() {
+			var buf bytes.Buffer
+			cfg.WithLogger(logger.New(&buf, logger.LevelInfo, logger.FormatText))
+
+			_, err := Compute(cfg, repo, esti)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(buf.String()).To(ContainSubstring(`msg="next version" version=1.1.0 bump=minor`))
+		})
+	})
+
+	When("ReleaseAs is set", func() {
+		var expectError = func(expectedSubstring string) func() {
+			return func() {
+				_, _, err := Bump(cfg, repo, esti)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(expectedSubstring))
+			}
+		}
+		var withReleaseAs = func(prerelease, releaseAs string) func() {
+			return func() {
+				cfg.Prerelease = prerelease
+				cfg.ReleaseAs = releaseAs
+				Expect(cfg.Valid()).ToNot(HaveOccurred())
+			}
+		}
+
+		BeforeEach(bedWith(
+			commits("one"),
+			lightweightTags("1.1.0"),
+			commits(majorLevelCommitMessage, "two"),
+		))
+
+		When("the version is greater than the latest release", func() {
+			BeforeEach(withReleaseAs("", "1.5.0"))
+			It("returns the given version and the commits since the latest release",
+				expectVersion("1.5.0", majorLevelCommitMessage, "two"))
+		})
+
+		DescribeTable("sets the level of the highest changed segment",
+			func(releaseAs string, expectedLevel BumpLevel) {
+				withReleaseAs("", releaseAs)()
+				result, err := Compute(cfg, repo, esti)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(result.Level).To(Equal(expectedLevel))
+			},
+			Entry("major", "5.0.0", BumpLevelMajor),
+			Entry("minor", "1.5.0", BumpLevelMinor),
+			Entry("patch", "1.1.7", BumpLevelPatch),
+		)
+
+		When("the version is not greater than the latest release", func() {
+			BeforeEach(withReleaseAs("", "1.1.0"))
+			It("returns an error", expectError("greater than the latest release"))
+		})
+
+		When("there are no tags yet", func() {
+			BeforeEach(func() {
+				cfg.ReleaseAs = "0.0.1"
+				Expect(cfg.Valid()).ToNot(HaveOccurred())
+				repo = aGitRepo(bed, func(o *Options) { o.TagPrefix = "v" })
+			})
+			It("returns the given version and all commits",
+				expectVersion("0.0.1", "one", majorLevelCommitMessage, "two"))
+		})
+
+		When("BumpPrerelease==true", func() {
+			BeforeEach(bedWith(lightweightTags(asPrerelease("1.2.0", 3))))
+
+			When("the version is greater than the latest prerelease", func() {
+				BeforeEach(withReleaseAs(testPrereleasePrefix, asPrerelease("1.2.0", 4)))
+				It("returns the given version", expectVersion(asPrerelease("1.2.0", 4)))
+			})
+
+			When("the version is not greater than the latest prerelease", func() {
+				BeforeEach(withReleaseAs(testPrereleasePrefix, asPrerelease("1.2.0", 2)))
+				It("returns an error", expectError("greater than the latest prerelease"))
+			})
+		})
+	})
+	When("AllowMajorBump is false", func() {
+		BeforeEach(func() {
+			cfg.AllowMajor = false
+			Expect(cfg.Valid()).ToNot(HaveOccurred())
+		})
+		BeforeEach(bedWith(commits("one"), lightweightTags("1.1.0"), commits(majorLevelCommitMessage, patchLevelCommitMessage)))
+
+		It("returns an error with the commits justifying a major bump", func() {
+			_, err := Compute(cfg, repo, esti)
+
+			var majorErr MajorBumpError
+			Expect(errors.As(err, &majorErr)).To(BeTrue())
+			Expect(majorErr.Reasons).To(HaveLen(1))
+			Expect(majorErr.Reasons[0].Commit.Message).To(Equal(majorLevelCommitMessage))
+			Expect(err.Error()).To(ContainSubstring(majorLevelCommitMessage))
+		})
+	})
+
+	When("a constraint is set", func() {
+		var withConstraint = func(prerelease, constraint string) func() {
+			return func() {
+				cfg.Prerelease = prerelease
+				cfg.Constraint = constraint
+				Expect(cfg.Valid()).ToNot(HaveOccurred())
+			}
+		}
+		var expectConstraintError = func() {
+			_, err := Compute(cfg, repo, esti)
+			Expect(err).To(MatchError(ContainSubstring("does not match the constraint")))
+		}
+
+		BeforeEach(bedWith(commits("one"), lightweightTags("1.1.0"), commits(minorLevelCommitMessage)))
+
+		When("the next version matches", func() {
+			BeforeEach(withConstraint("", "<2.0.0"))
+			It("returns the next version", expectVersion("1.2.0"))
+		})
+
+		When("the next version does not match", func() {
+			BeforeEach(withConstraint("", "<1.2.0"))
+			It("returns an error", expectConstraintError)
+		})
+
+		When("the next version is a prerelease of a version not matching", func() {
+			BeforeEach(withConstraint(testPrereleasePrefix, "<1.2.0"))
+			It("returns an error", expectConstraintError)
+		})
+
+		When("the next version is a prerelease of a matching version", func() {
+			BeforeEach(withConstraint(testPrereleasePrefix, "<2.0.0"))
+			It("returns the next version", expectVersion(asPrerelease1("1.2.0")))
+		})
+	})
+
+	When("the scheme is calendar versioning", func() {
+		const (
+			calVerFormat = "YY.0M.MICRO"
+			thisMonth    = "24.3"
+			thisMonthTag = "24.03"
+		)
+		now := time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			cfg = (&Options{Scheme: calVerFormat}).WithNow(func() time.Time { return now })
+			Expect(cfg.Valid()).ToNot(HaveOccurred())
+			repo = aGitRepo(bed, func(o *Options) { o.Scheme = calVerFormat })
+		})
+
+		When("there are no tags yet", func() {
+			BeforeEach(bedWith(commits("one")))
+			It("returns the first release of this month", expectVersion(thisMonth+".0"))
+		})
+
+		When("the latest release is from an earlier month", func() {
+			BeforeEach(bedWith(commits("one"), lightweightTags("20.01.4"), commits(patchLevelCommitMessage)))
+			It("returns the first release of this month", expectVersion(thisMonth+".0", patchLevelCommitMessage))
+		})
+
+		When("the latest release is from this month", func() {
+			BeforeEach(bedWith(commits("one"), lightweightTags("20.01.4", thisMonthTag+".2"), commits(majorLevelCommitMessage)))
+			It("increments the micro regardless of the level", expectVersion(thisMonth+".3", majorLevelCommitMessage))
+		})
+
+		When("BumpPrerelease==true", func() {
+			BeforeEach(func() {
+				cfg.Prerelease = testPrereleasePrefix
+				Expect(cfg.Valid()).ToNot(HaveOccurred())
+			})
+			BeforeEach(bedWith(commits("one"), lightweightTags("20.01.4", thisMonthTag+".0-"+testPrereleasePrefix+".1"), commits(minorLevelCommitMessage)))
+			It("bumps the prerelease of this month", expectVersion(asPrerelease(thisMonth+".0", 2)))
+		})
+
+		When("a tag does not match the format", func() {
+			BeforeEach(bedWith(commits("one"), lightweightTags("1.2.3"), commits(patchLevelCommitMessage)))
+			It("returns an error", func() {
+				_, _, err := Bump(cfg, repo, esti)
+				Expect(err).To(MatchError(ContainSubstring("does not match " + calVerFormat)))
+			})
+		})
+	})
 })
 
+type fakeAnalyzer struct {
+	changes []Change
+	err     error
+	since   *semver.Version
+}
+
+func (a *fakeAnalyzer) Analyze(since *semver.Version) ([]Change, error) {
+	a.since = since
+	return a.changes, a.err
+}
+
 func aConfiguration() *Options {
-	cfg := &Options{InitialVersion: testInitialVersion}
+	cfg := &Options{InitialVersion: testInitialVersion, AllowMajor: true}
 	Expect(cfg.Valid()).ToNot(HaveOccurred())
 
 	return cfg
@@ -232,8 +608,11 @@ func anEstimator() Estimator {
 	return estimator.NewEstimator(eCfg)
 }
 
-func aGitRepo(bed *TestbedRepo) GitRepo {
+func aGitRepo(bed *TestbedRepo, with ...func(*Options)) GitRepo {
 	rCfg := &Options{}
+	for _, fn := range with {
+		fn(rCfg)
+	}
 	Expect(rCfg.Valid()).ToNot(HaveOccurred())
 	repo, err := gitrepo.NewGitRepo(rCfg, bed.Path())
 	Expect(err).ToNot(HaveOccurred())
@@ -0,0 +1,87 @@
+package bumper
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/timotto/semver-bumper/pkg/model"
+)
+
+type (
+	// History is a git history replayed the oldest commit first
+	History interface {
+		LevelLimits(commit *object.Commit) (LevelLimits, error)
+		// CommitsUntil returns the commits reachable from the given commit which were not returned before
+		CommitsUntil(commit *object.Commit) ([]*object.Commit, error)
+	}
+
+	// Release is a simulated release of a replayed history
+	Release struct {
+		Version *semver.Version
+		Commit  *object.Commit
+		Commits []*object.Commit
+	}
+)
+
+// Replay simulates the releases at the given commits, the oldest first.
+// A commit becomes a release if the commits since the previous release justify a bump,
+// the first release is the initial version unless the history starts at a release.
+// Commits which are released already continue the replay with their version and are not returned.
+// Like Compute, it fails if a release is a major bump which is not allowed or does not match the constraint.
+func Replay(conf Config, history History, esti Estimator, since *object.Commit, at []*object.Commit, released map[plumbing.Hash]*semver.Version) ([]Release, error) {
+	var previous *semver.Version
+	if since != nil {
+		previous = released[since.Hash]
+	}
+
+	var result []Release
+	var commits []*object.Commit
+	for _, commit := range at {
+		more, err := history.CommitsUntil(commit)
+		if err != nil {
+			return nil, err
+		}
+
+		if v, ok := released[commit.Hash]; ok {
+			previous, commits = v, nil
+			continue
+		}
+
+		// the commits of candidates which did not become a release are part of the next one
+		if commits = append(commits, more...); len(commits) == 0 {
+			continue
+		}
+
+		reasons, err := commitReasons(history, esti, commits)
+		if err != nil {
+			return nil, err
+		}
+
+		lvl := esti.FallbackLevel()
+		for _, reason := range reasons {
+			lvl = lvl.Max(reason.Level)
+		}
+
+		// calendar versions are dated at the release commit
+		at := commit.Committer.When
+		switch {
+		case previous == nil:
+			previous = initialVersion(conf, at)
+		case lvl == BumpLevelNone:
+			continue
+		case lvl == BumpLevelMajor && !conf.AllowMajorBump():
+			return nil, MajorBumpError{Reasons: majorReasons(reasons)}
+		default:
+			previous = conf.SchemeValue().Next(previous, lvl, at)
+		}
+
+		if err := matchConstraint(conf, previous); err != nil {
+			return nil, err
+		}
+
+		result = append(result, Release{Version: previous, Commit: commit, Commits: commits})
+		commits = nil
+	}
+
+	return result, nil
+}
@@ -0,0 +1,110 @@
+package bumper_test
+
+import (
+	"errors"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/pkg/bumper"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	"github.com/timotto/semver-bumper/pkg/gitrepo"
+	. "github.com/timotto/semver-bumper/pkg/test/git_testbed"
+	"os"
+)
+
+var _ = Describe("Replay", func() {
+	var (
+		cfg      *Options
+		bed      *TestbedRepo
+		released map[plumbing.Hash]*semver.Version
+	)
+
+	BeforeEach(CreateBeforeEach(os.TempDir(), &bed))
+	AfterEach(TeardownAfterEach(&bed))
+
+	BeforeEach(func() {
+		cfg = aConfiguration()
+		released = make(map[plumbing.Hash]*semver.Version)
+		bed.AddCommits("one", patchLevelCommitMessage, "two", minorLevelCommitMessage)
+	})
+
+	var tryReplay = func(since *object.Commit) ([]Release, error) {
+		repo := aGitRepo(bed).(*gitrepo.Gitrepo)
+		history, err := repo.NewHistory(since)
+		Expect(err).ToNot(HaveOccurred())
+		at, err := history.FirstParents()
+		Expect(err).ToNot(HaveOccurred())
+
+		return Replay(cfg, history, anEstimator(), since, at, released)
+	}
+	var replay = func(since *object.Commit) []Release {
+		releases, err := tryReplay(since)
+		Expect(err).ToNot(HaveOccurred())
+
+		return releases
+	}
+	var versionsOf = func(releases []Release) []string {
+		var result []string
+		for _, release := range releases {
+			result = append(result, release.Version.String()+" "+release.Commit.Message)
+		}
+		return result
+	}
+
+	It("releases the initial version at the root commit and bumps at every commit justifying a release", func() {
+		Expect(versionsOf(replay(nil))).To(Equal([]string{
+			testInitialVersion + " one",
+			"9.8.8 " + patchLevelCommitMessage,
+			"9.9.0 " + minorLevelCommitMessage,
+		}))
+	})
+
+	It("includes the commits since the previous release", func() {
+		releases := replay(nil)
+		Expect(messagesFrom(releases[2].Commits...)).To(ConsistOf("two", minorLevelCommitMessage))
+	})
+
+	It("continues with the version of released commits", func() {
+		released[bed.Commits()[2].Hash] = semver.MustParse("1.0.0")
+
+		Expect(versionsOf(replay(nil))).To(Equal([]string{
+			testInitialVersion + " one",
+			"1.1.0 " + minorLevelCommitMessage,
+		}))
+	})
+
+	It("starts with the release of the given commit", func() {
+		since := bed.Commits()[2]
+		released[since.Hash] = semver.MustParse("1.0.0")
+
+		Expect(versionsOf(replay(since))).To(Equal([]string{
+			"1.1.0 " + minorLevelCommitMessage,
+		}))
+	})
+
+	When("a release is a major bump", func() {
+		BeforeEach(func() {
+			bed.AddCommits(majorLevelCommitMessage)
+		})
+
+		It("fails if a major bump is not allowed", func() {
+			cfg.AllowMajor = false
+
+			_, err := tryReplay(nil)
+			var majorErr MajorBumpError
+			Expect(errors.As(err, &majorErr)).To(BeTrue())
+			Expect(majorErr.Reasons).To(HaveLen(1))
+			Expect(majorErr.Reasons[0].Commit.Message).To(Equal(majorLevelCommitMessage))
+		})
+
+		It("fails if a release does not match the constraint", func() {
+			cfg.Constraint = "<10.0.0"
+			Expect(cfg.Valid()).ToNot(HaveOccurred())
+
+			_, err := tryReplay(nil)
+			Expect(err).To(MatchError("version 10.0.0 does not match the constraint <10.0.0"))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	. "github.com/timotto/semver-bumper/pkg/model"
+)
+
+type Entry struct {
+	Level BumpLevel
+	Text  string
+}
+
+var sections = []struct {
+	level BumpLevel
+	title string
+}{
+	{BumpLevelMajor, "Breaking changes"},
+	{BumpLevelMinor, "Features"},
+	{BumpLevelPatch, "Fixes"},
+	{BumpLevelNone, "Other changes"},
+}
+
+// Markdown groups the entries by their bump level, keeping their order within each group.
+func Markdown(version string, entries []Entry) string {
+	buf := &bytes.Buffer{}
+	_, _ = fmt.Fprintf(buf, "## %v\n", version)
+
+	for _, section := range sections {
+		first := true
+		for _, entry := range entries {
+			if entry.Level != section.level {
+				continue
+			}
+
+			if first {
+				_, _ = fmt.Fprintf(buf, "\n### %v\n\n", section.title)
+				first = false
+			}
+
+			_, _ = fmt.Fprintf(buf, "- %v\n", entry.Text)
+		}
+	}
+
+	return buf.String()
+}
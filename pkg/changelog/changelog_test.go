@@ -0,0 +1,41 @@
+package changelog_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/pkg/changelog"
+	. "github.com/timotto/semver-bumper/pkg/model"
+)
+
+var _ = Describe("Changelog", func() {
+	Describe("Markdown", func() {
+		It("groups the entries by bump level", func() {
+			actualResult := Markdown("1.2.0", []Entry{
+				{Level: BumpLevelPatch, Text: "fix: first"},
+				{Level: BumpLevelNone, Text: "other"},
+				{Level: BumpLevelMinor, Text: "feat: feature"},
+				{Level: BumpLevelPatch, Text: "fix: second"},
+			})
+
+			Expect(actualResult).To(Equal(`## 1.2.0
+
+### Features
+
+- feat: feature
+
+### Fixes
+
+- fix: first
+- fix: second
+
+### Other changes
+
+- other
+`))
+		})
+
+		It("prints only the version without entries", func() {
+			Expect(Markdown("1.0.0", nil)).To(Equal("## 1.0.0\n"))
+		})
+	})
+})
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	EnvPrefix        = "SEMVER_BUMPER_"
+	EnvListSeparator = EnvPrefix + "LIST_SEPARATOR"
+	EnvSource        = "environment"
+
+	defaultEnvListSeparator = ","
+)
+
+// EnvName is the environment variable name of the command line option with the given long name, eg
+// "SEMVER_BUMPER_TAG_PREFIX" for "tag-prefix"
+func EnvName(long string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(long, "-", "_"))
+}
+
+// FromEnv reads the options from SEMVER_BUMPER_* environment variables given as "key=value" pairs.
+// List values are split on SEMVER_BUMPER_LIST_SEPARATOR, which defaults to ",". Map entries are given
+// as "key:value", just like on the command line.
+func FromEnv(environ []string) (*Options, error) {
+	vars := make(map[string]string)
+	for _, kv := range environ {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], EnvPrefix) {
+			continue
+		}
+		vars[parts[0]] = parts[1]
+	}
+
+	separator := defaultEnvListSeparator
+	if value := vars[EnvListSeparator]; value != "" {
+		separator = value
+	}
+
+	result := Options{}
+	var problems Problems
+	value := reflect.ValueOf(&result).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		long := field.Tag.Get("long")
+		if long == "" {
+			continue
+		}
+
+		name := EnvName(long)
+		raw, ok := vars[name]
+		if !ok || raw == "" {
+			continue
+		}
+
+		if err := setFromEnv(value.Field(i), raw, separator); err != nil {
+			problems = append(problems, Problem{Source: EnvSource, Field: name, Err: err})
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+
+	if err := result.Validate(EnvSource); err != nil {
+		return nil, err
+	}
+	result.setSource(EnvSource)
+
+	return &result, nil
+}
+
+func setFromEnv(field reflect.Value, raw, separator string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean value %q", raw)
+		}
+		field.SetBool(b)
+
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		field.SetInt(int64(n))
+
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.Bool {
+			// a repeated flag like -vv is given as its count
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid number %q", raw)
+			}
+			field.Set(reflect.ValueOf(make([]bool, n)))
+			return nil
+		}
+		field.Set(reflect.ValueOf(splitList(raw, separator)))
+
+	case reflect.Map:
+		m := make(map[string]string)
+		for _, entry := range splitList(raw, separator) {
+			kv := strings.SplitN(entry, ":", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("invalid map entry %q, expected \"key:value\"", entry)
+			}
+			m[kv[0]] = kv[1]
+		}
+		field.Set(reflect.ValueOf(m))
+
+	default:
+		return fmt.Errorf("unsupported option type %v", field.Type())
+	}
+
+	return nil
+}
+
+func splitList(raw, separator string) []string {
+	var result []string
+	for _, item := range strings.Split(raw, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+func lookupEnv(environ []string, key string) string {
+	prefix := key + "="
+	for _, kv := range environ {
+		if strings.HasPrefix(kv, prefix) {
+			return strings.TrimPrefix(kv, prefix)
+		}
+	}
+
+	return ""
+}
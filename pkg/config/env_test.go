@@ -0,0 +1,77 @@
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/pkg/config"
+)
+
+var _ = Describe("Environment", func() {
+	DescribeTable(
+		"EnvName",
+		func(long, expected string) {
+			Expect(EnvName(long)).To(Equal(expected))
+		},
+		Entry("single word", "pre", "SEMVER_BUMPER_PRE"),
+		Entry("dashes", "tag-prefix", "SEMVER_BUMPER_TAG_PREFIX"),
+	)
+
+	Describe("FromEnv", func() {
+		It("ignores unrelated and empty variables", func() {
+			actual, err := FromEnv([]string{"HOME=/root", "SEMVER_BUMPER_PRE=", "SEMVER_BUMPER_UNKNOWN=x"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*actual).To(Equal(Options{}))
+		})
+
+		It("reads strings, booleans, numbers, lists, and maps", func() {
+			actual, err := FromEnv([]string{
+				"SEMVER_BUMPER_TAG_PREFIX=v",
+				"SEMVER_BUMPER_GO_API=true",
+				"SEMVER_BUMPER_MAX_COMMITS=1000",
+				"SEMVER_BUMPER_VERBOSE=2",
+				"SEMVER_BUMPER_PATH_EXCLUDE=docs, *.md",
+				"SEMVER_BUMPER_PATH_MAX=docs:patch,test:none",
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual.TagPrefix).To(Equal("v"))
+			Expect(actual.GoApi).To(BeTrue())
+			Expect(actual.MaxCommits).To(Equal(1000))
+			Expect(actual.Verbose).To(HaveLen(2))
+			Expect(actual.PathExclude).To(Equal([]string{"docs", "*.md"}))
+			Expect(actual.PathMax).To(Equal(map[string]string{"docs": "patch", "test": "none"}))
+		})
+
+		It("splits lists on a custom separator", func() {
+			actual, err := FromEnv([]string{
+				"SEMVER_BUMPER_LIST_SEPARATOR=;",
+				"SEMVER_BUMPER_MAJOR=^(a,b):;^c:",
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual.KeywordsMajor).To(Equal([]string{"^(a,b):", "^c:"}))
+		})
+
+		It("reports malformed values by variable name", func() {
+			_, err := FromEnv([]string{
+				"SEMVER_BUMPER_GO_API=maybe",
+				"SEMVER_BUMPER_MAX_COMMITS=many",
+				"SEMVER_BUMPER_PATH_MIN=api",
+			})
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("environment: SEMVER_BUMPER_GO_API: invalid boolean value"))
+			Expect(err.Error()).To(ContainSubstring("environment: SEMVER_BUMPER_MAX_COMMITS: invalid number"))
+			Expect(err.Error()).To(ContainSubstring("environment: SEMVER_BUMPER_PATH_MIN: invalid map entry"))
+		})
+
+		It("validates the values", func() {
+			_, err := FromEnv([]string{"SEMVER_BUMPER_NO_MATCH_BUMP=major"})
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(HavePrefix("environment: no_match_bump"))
+		})
+	})
+})
@@ -6,6 +6,7 @@ import (
 	"gopkg.in/yaml.v3"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 )
 
@@ -20,6 +21,11 @@ func FromFile(filename string) (*Options, error) {
 		return nil, fmt.Errorf("failed to decode configuration: %w", err)
 	}
 
+	if err := result.Validate(filename); err != nil {
+		return nil, err
+	}
+	result.setSource(filename)
+
 	return &result, nil
 }
 
@@ -38,34 +44,104 @@ func (o *Options) WriteToFile(filename string) error {
 		return fmt.Errorf("failed to encode configuration: %w", err)
 	}
 
-	if err := os.WriteFile(o.WriteConfig, data, 0644); err != nil {
+	if err := os.WriteFile(filename, data, 0644); err != nil {
 		return fmt.Errorf("failed to write file %v: %w", filename, err)
 	}
 
 	return nil
 }
 
-func SearchConfigFile(gitRepoPath, configFileArgument string) (string, error) {
+const (
+	projectConfigName = ".semver-bumper.conf"
+	userConfigDir     = "semver-bumper"
+	userConfigName    = "config"
+)
+
+// SearchConfigFiles returns the config files to load, the one with the highest precedence first.
+// The given config file replaces the project config files, which are searched from gitRepoPath up to
+// the root of the git repository. The user config file in $XDG_CONFIG_HOME/semver-bumper has the lowest
+// precedence.
+func SearchConfigFiles(environ []string, gitRepoPath, configFileArgument string) ([]string, error) {
+	var result []string
 	if configFileArgument != "" {
-		return configFileArgument, nil
+		result = append(result, configFileArgument)
+	} else {
+		for _, dir := range projectDirs(gitRepoPath) {
+			filename, err := searchConfigFile(dir, projectConfigName, "", ".yaml", ".yml", ".json")
+			if err != nil {
+				return nil, err
+			}
+			if filename != "" {
+				result = append(result, filename)
+			}
+		}
+	}
+
+	if dir, ok := userConfigHome(environ); ok {
+		filename, err := searchConfigFile(path.Join(dir, userConfigDir), userConfigName, ".yaml", ".yml", ".json")
+		if err != nil {
+			return nil, err
+		}
+		if filename != "" {
+			result = append(result, filename)
+		}
+	}
+
+	return result, nil
+}
+
+// projectDirs returns gitRepoPath and its parents up to the one containing .git,
+// or just gitRepoPath if it is not inside a git repository
+func projectDirs(gitRepoPath string) []string {
+	dir, err := filepath.Abs(gitRepoPath)
+	if err != nil {
+		return []string{gitRepoPath}
 	}
 
-	var projectConfigs []string
-	for _, suffix := range []string{"", ".yaml", ".yml", ".json"} {
-		filename := path.Join(gitRepoPath, fmt.Sprintf(".semver-bumper.conf%s", suffix))
+	var result []string
+	for {
+		result = append(result, dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return result
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return []string{gitRepoPath}
+		}
+		dir = parent
+	}
+}
+
+func userConfigHome(environ []string) (string, bool) {
+	if dir := lookupEnv(environ, "XDG_CONFIG_HOME"); dir != "" {
+		return dir, true
+	}
+
+	if home := lookupEnv(environ, "HOME"); home != "" {
+		return path.Join(home, ".config"), true
+	}
+
+	return "", false
+}
+
+func searchConfigFile(dir, name string, suffixes ...string) (string, error) {
+	var found []string
+	for _, suffix := range suffixes {
+		filename := path.Join(dir, name+suffix)
 		if !fileExists(filename) {
 			continue
 		}
-		projectConfigs = append(projectConfigs, filename)
+		found = append(found, filename)
 	}
 
-	switch len(projectConfigs) {
+	switch len(found) {
 	case 0:
 		return "", nil
 	case 1:
-		return projectConfigs[0], nil
+		return found[0], nil
 	default:
-		return "", fmt.Errorf("multiple project configuration files found: %v", projectConfigs)
+		return "", fmt.Errorf("multiple configuration files found: %v", found)
 	}
 }
 
@@ -0,0 +1,80 @@
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	"os"
+	"path/filepath"
+)
+
+var _ = Describe("SearchConfigFiles", func() {
+	var (
+		root, sub, xdg string
+		environ        []string
+	)
+	var touch = func(elem ...string) string {
+		filename := filepath.Join(elem...)
+		Expect(os.MkdirAll(filepath.Dir(filename), 0755)).To(Succeed())
+		Expect(os.WriteFile(filename, []byte("{}"), 0644)).To(Succeed())
+		return filename
+	}
+	BeforeEach(func() {
+		var err error
+		root, err = os.MkdirTemp("", "config-test-")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.Mkdir(filepath.Join(root, ".git"), 0755)).To(Succeed())
+		sub = filepath.Join(root, "a", "b")
+		Expect(os.MkdirAll(sub, 0755)).To(Succeed())
+		xdg = filepath.Join(root, "xdg")
+		environ = []string{"XDG_CONFIG_HOME=" + xdg}
+	})
+	AfterEach(func() {
+		Expect(os.RemoveAll(root)).To(Succeed())
+	})
+
+	It("returns the project config files from the directory up to the repository root, nearest first", func() {
+		rootFile := touch(root, ".semver-bumper.conf.yaml")
+		subFile := touch(sub, ".semver-bumper.conf")
+
+		Expect(SearchConfigFiles(environ, sub, "")).To(Equal([]string{subFile, rootFile}))
+	})
+
+	It("stops at the .git file of a worktree", func() {
+		Expect(os.RemoveAll(filepath.Join(root, ".git"))).To(Succeed())
+		worktree := filepath.Join(root, "a")
+		touch(root, ".semver-bumper.conf")
+		Expect(os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: ../.git/worktrees/a\n"), 0644)).To(Succeed())
+		worktreeFile := touch(worktree, ".semver-bumper.conf")
+
+		Expect(SearchConfigFiles(environ, sub, "")).To(Equal([]string{worktreeFile}))
+	})
+
+	It("appends the user config file with the lowest precedence", func() {
+		projectFile := touch(root, ".semver-bumper.conf.json")
+		userFile := touch(xdg, "semver-bumper", "config.yaml")
+
+		Expect(SearchConfigFiles(environ, root, "")).To(Equal([]string{projectFile, userFile}))
+	})
+
+	It("falls back to ~/.config for the user config file", func() {
+		userFile := touch(root, "home", ".config", "semver-bumper", "config.yml")
+
+		Expect(SearchConfigFiles([]string{"HOME=" + filepath.Join(root, "home")}, root, "")).To(Equal([]string{userFile}))
+	})
+
+	It("replaces the project config files with the given config file", func() {
+		touch(root, ".semver-bumper.conf")
+		userFile := touch(xdg, "semver-bumper", "config.yaml")
+
+		Expect(SearchConfigFiles(environ, root, "expected")).To(Equal([]string{"expected", userFile}))
+	})
+
+	It("fails if there are multiple config files in one directory", func() {
+		touch(root, ".semver-bumper.conf")
+		touch(root, ".semver-bumper.conf.yml")
+
+		_, err := SearchConfigFiles(environ, sub, "")
+		Expect(err).To(HaveOccurred())
+	})
+})
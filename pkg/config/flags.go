@@ -3,27 +3,71 @@ package config
 import (
 	"fmt"
 	"github.com/jessevdk/go-flags"
+	"reflect"
 )
 
+// NewParser returns the command line parser of the options, commands can be added to it
+func NewParser(opts *Options) *flags.Parser {
+	return flags.NewParser(opts, flags.HelpFlag|flags.PassDoubleDash)
+}
+
 func FromOsArgs(osArgs []string) (*Options, string, error) {
 	opts := Options{}
-	args, err := flags.
-		NewParser(&opts, flags.HelpFlag|flags.PassDoubleDash).
-		ParseArgs(osArgs)
+	args, err := NewParser(&opts).ParseArgs(osArgs[1:])
+	if err != nil {
+		return nil, "", err
+	}
+
+	gitRepoPath, err := GitRepoPath(args)
 	if err != nil {
 		return nil, "", err
 	}
-	args = args[1:]
+	opts.Parsed()
 
-	var gitRepoPath string
+	return &opts, gitRepoPath, nil
+}
+
+// GitRepoPath returns the git repository path given in the remaining command line arguments
+func GitRepoPath(args []string) (string, error) {
 	switch len(args) {
 	case 0:
-		gitRepoPath = "."
+		return ".", nil
 	case 1:
-		gitRepoPath = args[0]
+		return args[0], nil
 	default:
-		return nil, "", fmt.Errorf("too many arguments: %v", args[1:])
+		return "", fmt.Errorf("too many arguments: %v", args[1:])
 	}
+}
 
-	return &opts, gitRepoPath, nil
+// Parsed completes the options after parsing the command line
+func (o *Options) Parsed() {
+	o.normalize()
+	o.setSource(FlagSource)
+}
+
+// normalize unsets empty maps and removes empty list items. A list given with empty items only,
+// eg "--path-exclude=", stays empty instead of being filled from the environment, the config files,
+// or the defaults.
+func (o *Options) normalize() {
+	forEachKey(o, func(_ string, _ reflect.StructField, value reflect.Value) {
+		switch val := value.Interface().(type) {
+		case map[string]string:
+			if len(val) == 0 {
+				value.Set(reflect.Zero(value.Type()))
+			}
+
+		case []string:
+			if val == nil {
+				return
+			}
+
+			result := []string{}
+			for _, item := range val {
+				if item != "" {
+					result = append(result, item)
+				}
+			}
+			value.Set(reflect.ValueOf(result))
+		}
+	})
 }
@@ -1,9 +1,13 @@
 package config
 
 import (
-	"fmt"
 	"github.com/Masterminds/semver/v3"
+	"github.com/timotto/semver-bumper/pkg/logger"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"github.com/timotto/semver-bumper/pkg/scheme"
 	"reflect"
+	"regexp"
+	"time"
 )
 
 const (
@@ -12,40 +16,120 @@ const (
 
 	fallbackStrategyNone  = "none"
 	fallbackStrategyPatch = "patch"
+
+	OutputFormatVersion = "version"
+	OutputFormatDotenv  = "dotenv"
+	OutputFormatExport  = "export"
+	OutputFormatGithub  = "github"
+	OutputFormatGitlab  = "gitlab"
+
+	PathSyntaxGlob      = "glob"
+	PathSyntaxGitignore = "gitignore"
 )
 
 type FallbackStrategy int
 
 type Options struct {
 	ConfigFile  string `json:"-" yaml:"-" short:"C" long:"config-file" description:"load parameters from a JSON or YAML file"`
+	Extends     string `json:"extends,omitempty" yaml:"extends,omitempty" long:"preset" description:"extend a built-in configuration preset: conventional, angular, gitmoji, or emoji-bumper"`
 	TagPrefix   string `json:"tag_prefix,omitempty" yaml:"tag_prefix,omitempty" short:"t" long:"tag-prefix" description:"only detect tags matching the expression, eg \"v\" for \"v1.2.3\""`
+	Scheme      string `json:"scheme,omitempty" yaml:"scheme,omitempty" long:"scheme" description:"version scheme: semver, or a calendar versioning format like \"YYYY.MM.MICRO\" or \"YY.0M.MICRO\", defaults to semver"`
 	NoMatchBump string `json:"no_match_bump,omitempty" yaml:"no_match_bump,omitempty" short:"n" long:"no-match-bump" choice:"none" choice:"patch" description:"bump patch or nothing when no commits match"`
 
 	Prerelease     string `json:"pre,omitempty" yaml:"pre,omitempty" short:"p" long:"pre" description:"bump prerelease with given keyword, eg \"rc\" for \"1.2.3-rc.4\"'"`
-	FakePrerelease string `long:"fake-prerelease" description:"Pretend there is a given prerelease tag, in case git tags exist for releases only, eg \"1.2.3-rc.3\""`
+	FakePrerelease string `json:"-" yaml:"-" long:"fake-prerelease" description:"Pretend there is a given prerelease tag, in case git tags exist for releases only, eg \"1.2.3-rc.3\""`
 
-	Output  string `json:"output,omitempty" yaml:"output,omitempty" short:"o" long:"output" description:"write result into file, defaults to stdout"`
-	Commits string `json:"commits,omitempty" yaml:"commits,omitempty" short:"c" long:"commits" description:"write commit messages into file"`
+	Output       string `json:"output,omitempty" yaml:"output,omitempty" short:"o" long:"output" description:"write result into file, defaults to stdout, or to $GITHUB_OUTPUT for the github output format"`
+	OutputFormat string `json:"output_format,omitempty" yaml:"output_format,omitempty" long:"output-format" description:"format of the result: version, dotenv, export, github, or gitlab, the key/value formats have VERSION, PREVIOUS_VERSION, BUMP_LEVEL, TAG, and IS_PRERELEASE"`
+	Commits      string `json:"commits,omitempty" yaml:"commits,omitempty" short:"c" long:"commits" description:"write commit messages into file"`
+	Changelog    string `json:"changelog,omitempty" yaml:"changelog,omitempty" long:"changelog" description:"write a markdown changelog into file"`
 
-	PathInclude []string `json:"path_include,omitempty" yaml:"path_include,omitempty" short:"i" long:"path-include" description:"only detect commits at the given path, can be supplied multiple times"`
-	PathExclude []string `json:"path_exclude,omitempty" yaml:"path_exclude,omitempty" short:"x" long:"path-exclude" description:"ignore commits at the given path, can be supplied multiple times"`
+	PathInclude  []string          `json:"path_include,omitempty" yaml:"path_include,omitempty" short:"i" long:"path-include" description:"only detect commits at the given path, can be supplied multiple times"`
+	PathExclude  []string          `json:"path_exclude,omitempty" yaml:"path_exclude,omitempty" short:"x" long:"path-exclude" description:"ignore commits at the given path, can be supplied multiple times"`
+	PathSyntax   string            `json:"path_syntax,omitempty" yaml:"path_syntax,omitempty" long:"path-syntax" description:"syntax of the path include and exclude patterns: glob matches the file and each parent directory, gitignore-like supports **, anchored and directory-only patterns, and ! re-inclusion even below an excluded directory, defaults to glob"`
+	PathMax      map[string]string `json:"path_max,omitempty" yaml:"path_max,omitempty" long:"path-max" description:"highest bump level for commits changing only the given path, eg \"docs:patch\", matched like the glob path syntax, can be supplied multiple times"`
+	PathMin      map[string]string `json:"path_min,omitempty" yaml:"path_min,omitempty" long:"path-min" description:"lowest bump level for commits changing the given path, eg \"api/proto:minor\", matched like the glob path syntax, can be supplied multiple times"`
+	MaxCommits   int               `json:"max_commits,omitempty" yaml:"max_commits,omitempty" long:"max-commits" description:"fail when the history walk visits more than the given number of commits, 0 for no limit"`
+	CommitGraph  bool              `json:"commit_graph,omitempty" yaml:"commit_graph,omitempty" long:"commit-graph" description:"walk the history using the commit-graph file of the repository, if present"`
+	ScopeToDir   bool              `json:"scope_to_dir,omitempty" yaml:"scope_to_dir,omitempty" long:"scope-to-dir" description:"only detect commits changing the directory the tool runs in, when it is a subdirectory of the git repository"`
+	AllowShallow bool              `json:"allow_shallow,omitempty" yaml:"allow_shallow,omitempty" long:"allow-shallow" description:"allow a shallow clone if the history since the latest release tag is present"`
 
 	InitialVersion string   `json:"initial_version,omitempty" yaml:"initial_version,omitempty" short:"0" long:"initial-version" description:"release version if there are no tags yet, defaults to \"1.0.0\""`
+	ReleaseAs      string   `json:"release_as,omitempty" yaml:"release_as,omitempty" long:"release-as" description:"release the given version instead of estimating one, must be greater than the latest release"`
+	Constraint     string   `json:"constraint,omitempty" yaml:"constraint,omitempty" long:"constraint" description:"fail if the next version does not match the constraint, eg \"<3.0.0\""`
+	AllowMajor     bool     `json:"allow_major,omitempty" yaml:"allow_major,omitempty" long:"allow-major" description:"allow a major version bump, otherwise fail with the commits justifying it"`
 	KeywordsMajor  []string `json:"keywords_major,omitempty" yaml:"keywords_major,omitempty" short:"1" long:"major" description:"commit message keywords justifying a major version bump, can be supplied multiple times"`
 	KeywordsMinor  []string `json:"keywords_minor,omitempty" yaml:"keywords_minor,omitempty" short:"2" long:"minor" description:"commit message keywords justifying a minor version bump, can be supplied multiple times"`
 	KeywordsPatch  []string `json:"keywords_patch,omitempty" yaml:"keywords_patch,omitempty" short:"3" long:"patch" description:"commit message keywords justifying a patch version bump, can be supplied multiple times"`
 
-	PrintKeywords bool   `json:"-" yaml:"-" short:"k" long:"print-keywords" description:"print the configured version bump keywords and exit"`
-	WriteConfig   string `json:"-" yaml:"-" short:"W" long:"write-config" description:"write the given parameters into a JSON or YAML config file and exit"`
+	GoApi           bool     `json:"go_api,omitempty" yaml:"go_api,omitempty" long:"go-api" description:"compare the exported Go API of the latest release with HEAD to estimate the bump level"`
+	OpenApi         []string `json:"openapi,omitempty" yaml:"openapi,omitempty" long:"openapi" description:"compare the given OpenAPI or JSON schema file of the latest release with HEAD to estimate the bump level, can be supplied multiple times"`
+	FailUnderstated bool     `json:"fail_understated,omitempty" yaml:"fail_understated,omitempty" long:"fail-understated" description:"fail when the commit messages justify a lower bump level than the detected code changes"`
+	FailNoMatch     bool     `json:"fail_no_match,omitempty" yaml:"fail_no_match,omitempty" long:"fail-no-match" description:"fail when any commit since the latest release matches no keyword"`
+
+	Verbose        []bool `json:"-" yaml:"-" short:"v" long:"verbose" description:"log the tags, the commit range, the filter decisions, and the keyword matches to stderr, -vv for debug messages"`
+	LogFormat      string `json:"log_format,omitempty" yaml:"log_format,omitempty" long:"log-format" description:"format of the log messages: text or json, defaults to text"`
+	Explain        bool   `json:"-" yaml:"-" long:"explain" description:"print the reasons for the version bump to stderr"`
+	ValidateConfig bool   `json:"-" yaml:"-" long:"validate-config" description:"report all problems of the configuration and exit"`
+	PrintConfig    string `json:"-" yaml:"-" long:"print-config" optional:"yes" optional-value:"yaml" choice:"yaml" choice:"json" description:"print the effective configuration with the source of each value and exit"`
+	PrintKeywords  bool   `json:"-" yaml:"-" short:"k" long:"print-keywords" description:"print the configured version bump keywords and exit"`
+	WriteConfig    string `json:"-" yaml:"-" short:"W" long:"write-config" description:"write the given parameters into a JSON or YAML config file and exit"`
 
+	versionScheme  scheme.Scheme
 	initialVersion *semver.Version
+	releaseAs      *semver.Version
+	constraint     *semver.Constraints
 	noMatchBump    FallbackStrategy
+	pathMax        map[string]BumpLevel
+	pathMin        map[string]BumpLevel
+	keywordsMajor  []*regexp.Regexp
+	keywordsMinor  []*regexp.Regexp
+	keywordsPatch  []*regexp.Regexp
+	sources        map[string]string
+	now            func() time.Time
+	log            *logger.Logger
+}
+
+// WithLogger sets the logger of LoggerValue
+func (o *Options) WithLogger(log *logger.Logger) *Options {
+	o.log = log
+	return o
 }
 
+// LoggerValue receives the latest versions, the bump level, and the next version, nil discards them
+func (o *Options) LoggerValue() *logger.Logger {
+	return o.log
+}
+
+// WithNow sets the clock of Now, eg to pin the date of a calendar version
+func (o *Options) WithNow(now func() time.Time) *Options {
+	o.now = now
+	return o
+}
+
+// Now is the date of a calendar version, the current time unless WithNow sets a clock
+func (o *Options) Now() time.Time {
+	if o.now == nil {
+		return time.Now()
+	}
+
+	return o.now()
+}
+
+// InitialVersionValue is nil if no initial version is given for a calendar versioning scheme
 func (o *Options) InitialVersionValue() *semver.Version {
 	return o.initialVersion
 }
 
+// SchemeValue is semantic versioning unless a calendar versioning format is given
+func (o *Options) SchemeValue() scheme.Scheme {
+	if o.versionScheme == nil {
+		return scheme.SemVer{}
+	}
+
+	return o.versionScheme
+}
+
 func (o *Options) ReadConfigFile() bool {
 	return o.ConfigFile != ""
 }
@@ -62,20 +146,128 @@ func (o *Options) ShouldFakePrerelease() (string, bool) {
 	return o.FakePrerelease, o.FakePrerelease != ""
 }
 
+func (o *Options) ShouldReleaseAs() (*semver.Version, bool) {
+	return o.releaseAs, o.releaseAs != nil
+}
+
+func (o *Options) ShouldMatchConstraint() (*semver.Constraints, bool) {
+	return o.constraint, o.constraint != nil
+}
+
+// AllowMajorBump is always true for calendar versioning, where a major bump level does not change the major segment
+func (o *Options) AllowMajorBump() bool {
+	return o.AllowMajor || !scheme.IsSemVer(o.Scheme)
+}
+
 func (o *Options) NoMatchBumpValue() FallbackStrategy {
 	return o.noMatchBump
 }
 
+func (o *Options) PathMaxValue() map[string]BumpLevel {
+	return o.pathMax
+}
+
+func (o *Options) PathMinValue() map[string]BumpLevel {
+	return o.pathMin
+}
+
+func (o *Options) ShouldLimitCommits() (int, bool) {
+	return o.MaxCommits, o.MaxCommits > 0
+}
+
+func (o *Options) UseCommitGraph() bool {
+	return o.CommitGraph
+}
+
+func (o *Options) ShouldScopeToDir() bool {
+	return o.ScopeToDir
+}
+
+func (o *Options) AllowShallowClone() bool {
+	return o.AllowShallow
+}
+
+func (o *Options) FailOnUnderstatedLevel() bool {
+	return o.FailUnderstated
+}
+
+func (o *Options) FailOnNoMatch() bool {
+	return o.FailNoMatch
+}
+
+func (o *Options) KeywordsMajorValue() []*regexp.Regexp {
+	return o.keywordsMajor
+}
+
+func (o *Options) KeywordsMinorValue() []*regexp.Regexp {
+	return o.keywordsMinor
+}
+
+func (o *Options) KeywordsPatchValue() []*regexp.Regexp {
+	return o.keywordsPatch
+}
+
+// LogLevelValue is warn by default, info with -v, and debug with -vv
+func (o *Options) LogLevelValue() logger.Level {
+	switch {
+	case len(o.Verbose) >= 2:
+		return logger.LevelDebug
+	case len(o.Verbose) == 1:
+		return logger.LevelInfo
+	default:
+		return logger.LevelWarn
+	}
+}
+
+func (o *Options) LogFormatValue() string {
+	if o.LogFormat == "" {
+		return logger.FormatText
+	}
+
+	return o.LogFormat
+}
+
+func (o *Options) PathSyntaxValue() string {
+	if o.PathSyntax == "" {
+		return PathSyntaxGlob
+	}
+
+	return o.PathSyntax
+}
+
+func (o *Options) OutputFormatValue() string {
+	if o.OutputFormat == "" {
+		return OutputFormatVersion
+	}
+
+	return o.OutputFormat
+}
+
 func (o *Options) OutputCommits() bool {
 	return o.Commits != ""
 }
 
+func (o *Options) OutputChangelog() bool {
+	return o.Changelog != ""
+}
+
+func (o *Options) ShouldPrintConfig() bool {
+	return o.PrintConfig != ""
+}
+
+// SetMissingFrom fills the values which are not set from other, keeping track of their source.
+// An empty but not nil list is set, it clears the list of other.
 func (o *Options) SetMissingFrom(other *Options) {
 	higher := reflect.ValueOf(o).Elem()
 	lower := reflect.ValueOf(other).Elem()
 
 	n := higher.NumField()
 	for i := 0; i < n; i++ {
+		key, ok := configKey(higher.Type().Field(i))
+		if !ok {
+			continue
+		}
+
 		fieldHigher := higher.Field(i)
 		if !fieldHigher.IsZero() {
 			continue
@@ -87,39 +279,41 @@ func (o *Options) SetMissingFrom(other *Options) {
 		}
 
 		fieldHigher.Set(fieldLower)
+		o.setSourceOf(key, other.sources[key])
 	}
 }
 
+// Valid applies the defaults and checks the values merged from all sources
 func (o *Options) Valid() error {
-	if o.InitialVersion == "" {
+	if err := o.ApplyPreset(); err != nil {
+		return Problems{{Field: "extends", Err: err}}
+	}
+
+	// the initial calendar version is the date of the first release
+	if o.InitialVersion == "" && scheme.IsSemVer(o.Scheme) {
 		o.InitialVersion = "1.0.0"
+		o.setSourceOf("initial_version", DefaultSource)
 	}
 	if o.NoMatchBump == "" {
 		o.NoMatchBump = fallbackStrategyNone
+		o.setSourceOf("no_match_bump", DefaultSource)
 	}
-	if len(o.KeywordsMajor) == 0 {
+	if o.KeywordsMajor == nil {
 		o.KeywordsMajor = []string{"^BREAKING CHANGE:"}
+		o.setSourceOf("keywords_major", DefaultSource)
 	}
-	if len(o.KeywordsMinor) == 0 {
+	if o.KeywordsMinor == nil {
 		o.KeywordsMinor = []string{"^feat:"}
+		o.setSourceOf("keywords_minor", DefaultSource)
 	}
-	if len(o.KeywordsPatch) == 0 {
+	if o.KeywordsPatch == nil {
 		o.KeywordsPatch = []string{"^fix:", "^chore:"}
+		o.setSourceOf("keywords_patch", DefaultSource)
 	}
 
-	var err error
-	if o.initialVersion, err = semver.StrictNewVersion(o.InitialVersion); err != nil {
-		return fmt.Errorf("invalid initial version %v: %w", o.InitialVersion, err)
-	}
-
-	switch o.NoMatchBump {
-	case fallbackStrategyNone:
-		o.noMatchBump = FallbackStrategyNone
-	case fallbackStrategyPatch:
-		o.noMatchBump = FallbackStrategyPatch
-	default:
-		return fmt.Errorf("invalid no match bump value: %v", o.NoMatchBump)
-	}
+	v := &validator{sources: o.sources}
+	o.validateFields(v)
+	o.validateVersions(v)
 
-	return nil
+	return v.err()
 }
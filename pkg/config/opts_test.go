@@ -1,12 +1,17 @@
 package config_test
 
 import (
+	"errors"
 	"github.com/Masterminds/semver/v3"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/types"
 	. "github.com/timotto/semver-bumper/pkg/config"
+	"github.com/timotto/semver-bumper/pkg/logger"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"github.com/timotto/semver-bumper/pkg/scheme"
+	"regexp"
 )
 
 var _ = Describe("Options", func() {
@@ -66,6 +71,136 @@ var _ = Describe("Options", func() {
 				Entry("valid value: none", "none", BeNil()),
 				Entry("invalid value", "other", HaveOccurred()),
 			)
+			DescribeTable(
+				"PathMax and PathMin",
+				func(pattern, level string, expect types.GomegaMatcher) {
+					uut := &Options{PathMax: map[string]string{pattern: level}}
+					Expect(uut.Valid()).To(expect)
+					uut = &Options{PathMin: map[string]string{pattern: level}}
+					Expect(uut.Valid()).To(expect)
+				},
+				Entry("valid rule", "docs/*", "patch", BeNil()),
+				Entry("invalid level", "docs/*", "huge", HaveOccurred()),
+				Entry("invalid pattern", "docs/[", "patch", HaveOccurred()),
+			)
+			DescribeTable(
+				"ReleaseAs",
+				func(prerelease, val string, expect types.GomegaMatcher) {
+					uut := &Options{Prerelease: prerelease, ReleaseAs: val}
+					Expect(uut.Valid()).To(expect)
+				},
+				Entry("empty", "", "", BeNil()),
+				Entry("valid release", "", "1.2.3", BeNil()),
+				Entry("unexpected prefix", "", "v1.2.3", HaveOccurred()),
+				Entry("prerelease without prerelease keyword", "", "1.2.3-rc.1", HaveOccurred()),
+				Entry("valid prerelease", "rc", "1.2.3-rc.1", BeNil()),
+				Entry("release with prerelease keyword", "rc", "1.2.3", HaveOccurred()),
+				Entry("prerelease with a different keyword", "rc", "1.2.3-beta.1", HaveOccurred()),
+			)
+			DescribeTable(
+				"Scheme",
+				func(format, initialVersion string, expect types.GomegaMatcher) {
+					uut := &Options{Scheme: format, InitialVersion: initialVersion}
+					Expect(uut.Valid()).To(expect)
+				},
+				Entry("semver", "semver", "", BeNil()),
+				Entry("calendar versioning", "YY.0M.MICRO", "", BeNil()),
+				Entry("calendar versioning with initial version", "YY.0M.MICRO", "26.01.0", BeNil()),
+				Entry("initial version not matching the format", "YY.0M.MICRO", "1.0.0", HaveOccurred()),
+				Entry("invalid format", "YYYY-MM", "", HaveOccurred()),
+			)
+			DescribeTable(
+				"Constraint",
+				func(val string, expect types.GomegaMatcher) {
+					uut := &Options{Constraint: val}
+					Expect(uut.Valid()).To(expect)
+				},
+				Entry("valid constraint", "<3.0.0", BeNil()),
+				Entry("invalid constraint", "<three", HaveOccurred()),
+			)
+			DescribeTable(
+				"MaxCommits",
+				func(val int, expect types.GomegaMatcher) {
+					uut := &Options{MaxCommits: val}
+					Expect(uut.Valid()).To(expect)
+				},
+				Entry("no limit", 0, BeNil()),
+				Entry("a limit", 1000, BeNil()),
+				Entry("negative", -1, HaveOccurred()),
+			)
+			DescribeTable(
+				"PathSyntax",
+				func(val string, expect types.GomegaMatcher) {
+					uut := &Options{PathSyntax: val}
+					Expect(uut.Valid()).To(expect)
+				},
+				Entry("default", "", BeNil()),
+				Entry("glob", PathSyntaxGlob, BeNil()),
+				Entry("gitignore", PathSyntaxGitignore, BeNil()),
+				Entry("invalid syntax", "regexp", HaveOccurred()),
+			)
+			DescribeTable(
+				"LogFormat",
+				func(val string, expect types.GomegaMatcher) {
+					uut := &Options{LogFormat: val}
+					Expect(uut.Valid()).To(expect)
+				},
+				Entry("default", "", BeNil()),
+				Entry("text", logger.FormatText, BeNil()),
+				Entry("json", logger.FormatJSON, BeNil()),
+				Entry("invalid format", "xml", HaveOccurred()),
+			)
+		})
+
+		Describe("Problems", func() {
+			It("reports all problems at once with the field names", func() {
+				uut := &Options{
+					InitialVersion: "bad-version",
+					TagPrefix:      "bad prefix",
+					PathInclude:    []string{"fine", "bad["},
+					KeywordsMinor:  []string{"^fine", "bad("},
+				}
+
+				err := uut.Valid()
+
+				Expect(err).To(HaveOccurred())
+				var problems Problems
+				Expect(errors.As(err, &problems)).To(BeTrue())
+				Expect(problems).To(HaveLen(4))
+				Expect(err.Error()).To(ContainSubstring("initial_version: invalid initial version bad-version"))
+				Expect(err.Error()).To(ContainSubstring("tag_prefix: invalid tag prefix"))
+				Expect(err.Error()).To(ContainSubstring("path_include[1]: invalid path pattern"))
+				Expect(err.Error()).To(ContainSubstring(`keywords_minor[1]: invalid regular expression "bad("`))
+			})
+
+			It("prefixes the problems with the source", func() {
+				uut := &Options{NoMatchBump: "sometimes"}
+				err := uut.Validate("some-file.yaml")
+				Expect(err).To(MatchError("some-file.yaml: no_match_bump: invalid no match bump value: sometimes"))
+			})
+
+			DescribeTable(
+				"the values of one source",
+				func(uut *Options, expect types.GomegaMatcher) {
+					Expect(uut.Validate("some-file.yaml")).To(expect)
+				},
+				Entry("prerelease release-as without prerelease keyword", &Options{ReleaseAs: "2.0.0-rc.1"}, BeNil()),
+				Entry("calendar initial version without scheme", &Options{InitialVersion: "26.01.0"}, BeNil()),
+				Entry("invalid initial version", &Options{InitialVersion: "bad-version"}, HaveOccurred()),
+				Entry("invalid release-as version", &Options{ReleaseAs: "bad-version"}, HaveOccurred()),
+			)
+
+			DescribeTable(
+				"Prerelease",
+				func(val string, expect types.GomegaMatcher) {
+					uut := &Options{Prerelease: val}
+					Expect(uut.Valid()).To(expect)
+				},
+				Entry("simple keyword", "rc", BeNil()),
+				Entry("dotted keyword", "beta.x-1", BeNil()),
+				Entry("invalid character", "r c", HaveOccurred()),
+				Entry("empty identifier", "rc..x", HaveOccurred()),
+			)
 		})
 
 		Describe("Value objects", func() {
@@ -122,6 +257,128 @@ var _ = Describe("Options", func() {
 
 				})
 			})
+			Describe("PathMax and PathMin", func() {
+				It("makes them available as value objects", func() {
+					uut := &Options{
+						PathMax: map[string]string{"docs": "patch"},
+						PathMin: map[string]string{"api": "minor"},
+					}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					Expect(uut.PathMaxValue()).To(Equal(map[string]BumpLevel{"docs": BumpLevelPatch}))
+					Expect(uut.PathMinValue()).To(Equal(map[string]BumpLevel{"api": BumpLevelMinor}))
+				})
+			})
+			Describe("ReleaseAs", func() {
+				It("makes it available as value object", func() {
+					uut := &Options{}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					_, ok := uut.ShouldReleaseAs()
+					Expect(ok).To(BeFalse())
+
+					uut = &Options{ReleaseAs: "2.0.0"}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					v, ok := uut.ShouldReleaseAs()
+					Expect(ok).To(BeTrue())
+					Expect(v).To(Equal(semver.MustParse("2.0.0")))
+				})
+			})
+			Describe("Scheme", func() {
+				It("makes it available as value object without a default initial version for calendar versioning", func() {
+					uut := &Options{}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					Expect(uut.SchemeValue()).To(Equal(scheme.SemVer{}))
+
+					uut = &Options{Scheme: "YYYY.MM.MICRO"}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					Expect(uut.SchemeValue()).To(BeAssignableToTypeOf(&scheme.CalVer{}))
+					Expect(uut.InitialVersionValue()).To(BeNil())
+				})
+			})
+			Describe("Constraint and AllowMajor", func() {
+				It("makes them available as value objects", func() {
+					uut := &Options{}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					_, ok := uut.ShouldMatchConstraint()
+					Expect(ok).To(BeFalse())
+					Expect(uut.AllowMajorBump()).To(BeFalse())
+
+					uut = &Options{Constraint: "<3.0.0", AllowMajor: true}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					constraint, ok := uut.ShouldMatchConstraint()
+					Expect(ok).To(BeTrue())
+					Expect(constraint.Check(semver.MustParse("3.0.0"))).To(BeFalse())
+					Expect(uut.AllowMajorBump()).To(BeTrue())
+				})
+
+				It("always allows a major bump level for calendar versioning", func() {
+					uut := &Options{Scheme: "YYYY.MM.MICRO"}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					Expect(uut.AllowMajorBump()).To(BeTrue())
+				})
+			})
+			Describe("MaxCommits and CommitGraph", func() {
+				It("makes them available as value objects", func() {
+					uut := &Options{}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					_, ok := uut.ShouldLimitCommits()
+					Expect(ok).To(BeFalse())
+					Expect(uut.UseCommitGraph()).To(BeFalse())
+
+					uut = &Options{MaxCommits: 1000, CommitGraph: true}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					limit, ok := uut.ShouldLimitCommits()
+					Expect(ok).To(BeTrue())
+					Expect(limit).To(Equal(1000))
+					Expect(uut.UseCommitGraph()).To(BeTrue())
+				})
+			})
+			Describe("ScopeToDir", func() {
+				It("makes it available as value object", func() {
+					uut := &Options{}
+					Expect(uut.ShouldScopeToDir()).To(BeFalse())
+
+					uut = &Options{ScopeToDir: true}
+					Expect(uut.ShouldScopeToDir()).To(BeTrue())
+				})
+			})
+			Describe("AllowShallow", func() {
+				It("makes it available as value object", func() {
+					uut := &Options{}
+					Expect(uut.AllowShallowClone()).To(BeFalse())
+
+					uut = &Options{AllowShallow: true}
+					Expect(uut.AllowShallowClone()).To(BeTrue())
+				})
+			})
+			Describe("Verbose and LogFormat", func() {
+				It("makes them available as value objects", func() {
+					uut := &Options{}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					Expect(uut.LogLevelValue()).To(Equal(logger.LevelWarn))
+					Expect(uut.LogFormatValue()).To(Equal(logger.FormatText))
+
+					uut = &Options{Verbose: []bool{true}, LogFormat: logger.FormatJSON}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					Expect(uut.LogLevelValue()).To(Equal(logger.LevelInfo))
+					Expect(uut.LogFormatValue()).To(Equal(logger.FormatJSON))
+
+					uut = &Options{Verbose: []bool{true, true, true}}
+					Expect(uut.LogLevelValue()).To(Equal(logger.LevelDebug))
+				})
+			})
+			Describe("Keywords", func() {
+				It("makes them available as compiled regular expressions", func() {
+					uut := &Options{
+						KeywordsMajor: []string{"^major"},
+						KeywordsMinor: []string{"^minor", "feature$"},
+						KeywordsPatch: []string{"^patch"},
+					}
+					Expect(uut.Valid()).ToNot(HaveOccurred())
+					Expect(uut.KeywordsMajorValue()).To(Equal([]*regexp.Regexp{regexp.MustCompile("^major")}))
+					Expect(uut.KeywordsMinorValue()).To(Equal([]*regexp.Regexp{regexp.MustCompile("^minor"), regexp.MustCompile("feature$")}))
+					Expect(uut.KeywordsPatchValue()).To(Equal([]*regexp.Regexp{regexp.MustCompile("^patch")}))
+				})
+			})
 			Describe("Commits", func() {
 				It("provides a bool to check if it is set", func() {
 					uut := &Options{Commits: ""}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"embed"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// PresetSplice in a list is replaced with the list of the extended preset
+const PresetSplice = "..."
+
+//go:embed presets/*.yaml
+var presets embed.FS
+
+func Presets() []string {
+	entries, _ := presets.ReadDir("presets")
+
+	var result []string
+	for _, entry := range entries {
+		result = append(result, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func Preset(name string) (*Options, error) {
+	data, err := presets.ReadFile("presets/" + name + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("unknown preset %v, available presets are: %v", name, strings.Join(Presets(), ", "))
+	}
+
+	result := Options{}
+	if err := yaml.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode preset %v: %w", name, err)
+	}
+	result.setSource(PresetSource(name))
+
+	return &result, nil
+}
+
+// ApplyPreset fills the missing values from the extended preset.
+// Lists replace the list of the preset unless they contain PresetSplice,
+// maps are merged with the map of the preset.
+func (o *Options) ApplyPreset() error {
+	preset := &Options{}
+	if o.Extends != "" {
+		var err error
+		if preset, err = Preset(o.Extends); err != nil {
+			return err
+		}
+	}
+	source := PresetSource(o.Extends)
+
+	higher := reflect.ValueOf(o).Elem()
+	lower := reflect.ValueOf(preset).Elem()
+
+	n := higher.NumField()
+	for i := 0; i < n; i++ {
+		key, ok := configKey(higher.Type().Field(i))
+		if !ok {
+			continue
+		}
+
+		fieldHigher := higher.Field(i)
+		fieldLower := lower.Field(i)
+		switch val := fieldHigher.Interface().(type) {
+		case []string:
+			result, spliced := splice(val, fieldLower.Interface().([]string))
+			fieldHigher.Set(reflect.ValueOf(result))
+			if spliced && !fieldLower.IsZero() {
+				o.addSourceOf(key, source)
+			}
+
+		case map[string]string:
+			fieldHigher.Set(reflect.ValueOf(merge(val, fieldLower.Interface().(map[string]string))))
+			if !fieldLower.IsZero() {
+				o.addSourceOf(key, source)
+			}
+
+		default:
+			if fieldHigher.IsZero() && !fieldLower.IsZero() {
+				fieldHigher.Set(fieldLower)
+				o.setSourceOf(key, source)
+			}
+		}
+	}
+
+	return nil
+}
+
+// splice returns the resulting list and whether the lower list is part of it
+func splice(higher, lower []string) ([]string, bool) {
+	if higher == nil {
+		return lower, true
+	}
+
+	var result []string
+	var spliced bool
+	for _, item := range higher {
+		if item == PresetSplice {
+			result = append(result, lower...)
+			spliced = true
+			continue
+		}
+
+		result = append(result, item)
+	}
+
+	return result, spliced
+}
+
+func merge(higher, lower map[string]string) map[string]string {
+	if len(lower) == 0 {
+		return higher
+	}
+
+	result := make(map[string]string)
+	for key, val := range lower {
+		result[key] = val
+	}
+	for key, val := range higher {
+		result[key] = val
+	}
+
+	return result
+}
@@ -0,0 +1,81 @@
+package config_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	"regexp"
+)
+
+var _ = Describe("Preset", func() {
+	DescribeTable(
+		"built-in presets",
+		func(name, majorMessage, minorMessage, patchMessage string) {
+			preset, err := Preset(name)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(preset.Valid()).ToNot(HaveOccurred())
+
+			var expectMatch = func(keywords []string, message string) {
+				matches := false
+				for _, keyword := range keywords {
+					if regexp.MustCompile(keyword).MatchString(message) {
+						matches = true
+					}
+				}
+				Expect(matches).To(BeTrue(), message)
+			}
+			expectMatch(preset.KeywordsMajor, majorMessage)
+			expectMatch(preset.KeywordsMinor, minorMessage)
+			expectMatch(preset.KeywordsPatch, patchMessage)
+		},
+		Entry("conventional", "conventional", "feat(api)!: remove v1", "feat(api): add v2", "fix: bug"),
+		Entry("angular", "angular", "feat: new\n\nBREAKING CHANGE: old is gone", "feat(core): new", "perf: faster"),
+		Entry("gitmoji", "gitmoji", "💥 remove v1", ":sparkles: add v2", "🐛 fix bug"),
+		Entry("emoji-bumper", "emoji-bumper", "🤑 all new", "🎉 feature", "🔨 fix"),
+	)
+
+	It("lists the built-in presets", func() {
+		Expect(Presets()).To(Equal([]string{"angular", "conventional", "emoji-bumper", "gitmoji"}))
+	})
+
+	It("returns an error for unknown presets", func() {
+		_, err := Preset("unknown")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("gitmoji"))
+	})
+
+	Describe("ApplyPreset", func() {
+		It("uses the values of the preset for missing values", func() {
+			uut := &Options{Extends: "emoji-bumper", TagPrefix: "v"}
+			Expect(uut.Valid()).ToNot(HaveOccurred())
+			Expect(uut.TagPrefix).To(Equal("v"))
+			Expect(uut.KeywordsMajor).To(Equal([]string{"^🤑"}))
+		})
+
+		It("prefers local values over the preset", func() {
+			uut := &Options{Extends: "emoji-bumper", KeywordsMajor: []string{"^local"}}
+			Expect(uut.Valid()).ToNot(HaveOccurred())
+			Expect(uut.KeywordsMajor).To(Equal([]string{"^local"}))
+		})
+
+		It(`replaces "..." with the values of the preset`, func() {
+			uut := &Options{Extends: "emoji-bumper", KeywordsPatch: []string{"^first", PresetSplice, "^last"}}
+			Expect(uut.Valid()).ToNot(HaveOccurred())
+			Expect(uut.KeywordsPatch).To(Equal([]string{"^first", "^🔨", "^🛠️", "^last"}))
+		})
+
+		It(`removes "..." without a preset`, func() {
+			uut := &Options{KeywordsPatch: []string{"^first", PresetSplice}}
+			Expect(uut.Valid()).ToNot(HaveOccurred())
+			Expect(uut.KeywordsPatch).To(Equal([]string{"^first"}))
+		})
+
+		It("returns an error for unknown presets", func() {
+			uut := &Options{Extends: "unknown"}
+			err := uut.Valid()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("extends: unknown preset unknown"))
+		})
+	})
+})
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"strings"
+)
+
+const (
+	FlagSource    = "command line"
+	DefaultSource = "default"
+
+	PrintConfigYaml = "yaml"
+	PrintConfigJson = "json"
+)
+
+// PresetSource is the source of the values of the given preset
+func PresetSource(name string) string {
+	return "preset " + name
+}
+
+// SourceOf returns where the value of the given configuration key, eg "tag_prefix", came from
+func (o *Options) SourceOf(key string) string {
+	return o.sources[key]
+}
+
+// EncodeEffective encodes the effective configuration as YAML with the source of each value as comment,
+// or as JSON object with "config" and "sources" keys.
+func (o *Options) EncodeEffective(format string) ([]byte, error) {
+	switch format {
+	case PrintConfigJson:
+		sources := make(map[string]string)
+		forEachKey(o, func(key string, field reflect.StructField, value reflect.Value) {
+			if source := o.sources[key]; source != "" && !value.IsZero() && field.Tag.Get("json") != "-" {
+				sources[key] = source
+			}
+		})
+
+		data, err := json.MarshalIndent(struct {
+			Config  *Options          `json:"config"`
+			Sources map[string]string `json:"sources"`
+		}{o, sources}, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode configuration: %w", err)
+		}
+
+		return append(data, '\n'), nil
+
+	case PrintConfigYaml, "":
+		doc := &yaml.Node{}
+		if err := doc.Encode(o); err != nil {
+			return nil, fmt.Errorf("failed to encode configuration: %w", err)
+		}
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			key, value := doc.Content[i], doc.Content[i+1]
+			source := o.sources[key.Value]
+			switch {
+			case source == "":
+			case value.Kind == yaml.ScalarNode:
+				value.LineComment = source
+			default:
+				key.HeadComment = source
+			}
+		}
+
+		data, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode configuration: %w", err)
+		}
+
+		return data, nil
+
+	default:
+		return nil, fmt.Errorf("unknown configuration format %v", format)
+	}
+}
+
+// setSource records the source of all values that are set
+func (o *Options) setSource(source string) {
+	forEachKey(o, func(key string, _ reflect.StructField, value reflect.Value) {
+		if !value.IsZero() {
+			o.setSourceOf(key, source)
+		}
+	})
+}
+
+func (o *Options) setSourceOf(key, source string) {
+	if source == "" {
+		return
+	}
+	if o.sources == nil {
+		o.sources = make(map[string]string)
+	}
+
+	o.sources[key] = source
+}
+
+// addSourceOf records an additional source of a combined value, eg a list spliced into a preset
+func (o *Options) addSourceOf(key, source string) {
+	if existing := o.sources[key]; existing != "" && existing != source {
+		source = existing + ", " + source
+	}
+
+	o.setSourceOf(key, source)
+}
+
+// forEachKey calls fn for each exported field with a configuration key
+func forEachKey(o *Options, fn func(key string, field reflect.StructField, value reflect.Value)) {
+	value := reflect.ValueOf(o).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if key, ok := configKey(field); ok {
+			fn(key, field, value.Field(i))
+		}
+	}
+}
+
+// configKey is the YAML key of the field, or the long command line option for values not stored in files
+func configKey(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+
+	switch name := strings.Split(field.Tag.Get("yaml"), ",")[0]; name {
+	case "-":
+		return strings.ReplaceAll(field.Tag.Get("long"), "-", "_"), true
+	case "":
+		return strings.ToLower(field.Name), true
+	default:
+		return name, true
+	}
+}
@@ -0,0 +1,66 @@
+package config_test
+
+import (
+	"encoding/json"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/pkg/config"
+)
+
+var _ = Describe("Source", func() {
+	var uut *Options
+	BeforeEach(func() {
+		var err error
+		uut, _, err = FromOsArgs([]string{"semver-bumper", "--tag-prefix", "v", "--path-exclude="})
+		Expect(err).ToNot(HaveOccurred())
+
+		env, err := FromEnv([]string{"SEMVER_BUMPER_PRESET=gitmoji", "SEMVER_BUMPER_MINOR=^expected,...", "SEMVER_BUMPER_TAG_PREFIX=r"})
+		Expect(err).ToNot(HaveOccurred())
+		uut.SetMissingFrom(env)
+		uut.SetMissingFrom(&Options{PathExclude: []string{"docs"}, Prerelease: "rc"})
+
+		Expect(uut.Valid()).ToNot(HaveOccurred())
+	})
+
+	It("tracks the source of each value", func() {
+		Expect(uut.SourceOf("tag_prefix")).To(Equal(FlagSource))
+		Expect(uut.SourceOf("extends")).To(Equal(EnvSource))
+		Expect(uut.SourceOf("keywords_minor")).To(Equal(EnvSource + ", " + PresetSource("gitmoji")))
+		Expect(uut.SourceOf("keywords_major")).To(Equal(PresetSource("gitmoji")))
+		Expect(uut.SourceOf("initial_version")).To(Equal(DefaultSource))
+	})
+
+	It("lets a flag clear a list", func() {
+		Expect(uut.PathExclude).To(BeEmpty())
+		Expect(uut.SourceOf("path_exclude")).To(Equal(FlagSource))
+	})
+
+	Describe("EncodeEffective", func() {
+		It("annotates YAML values with their source", func() {
+			data, err := uut.EncodeEffective(PrintConfigYaml)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("tag_prefix: v # command line\n"))
+			Expect(string(data)).To(ContainSubstring("# preset gitmoji\nkeywords_major:\n"))
+		})
+
+		It("lists the sources next to the JSON values", func() {
+			data, err := uut.EncodeEffective(PrintConfigJson)
+			Expect(err).ToNot(HaveOccurred())
+
+			var actual struct {
+				Config  Options
+				Sources map[string]string
+			}
+			Expect(json.Unmarshal(data, &actual)).To(Succeed())
+			Expect(actual.Config.TagPrefix).To(Equal("v"))
+			Expect(actual.Sources).To(HaveKeyWithValue("tag_prefix", FlagSource))
+			Expect(actual.Sources).ToNot(HaveKey("print_config"))
+		})
+
+		It("rejects unknown formats", func() {
+			_, err := uut.EncodeEffective("xml")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,315 @@
+package config
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/timotto/semver-bumper/pkg/logger"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"github.com/timotto/semver-bumper/pkg/scheme"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const invalidTagPrefixCharacters = " ~^:?*[\\"
+
+var prereleaseKeyword = regexp.MustCompile(`^[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*$`)
+
+type (
+	// Problem is an invalid configuration value
+	Problem struct {
+		Source string
+		Field  string
+		Err    error
+	}
+
+	// Problems is the list of all invalid configuration values
+	Problems []Problem
+
+	// validator collects the problems of the values from one source, or of the merged values with their sources
+	validator struct {
+		source   string
+		sources  map[string]string
+		problems Problems
+	}
+)
+
+func (p Problem) Error() string {
+	var prefix string
+	if p.Source != "" {
+		prefix = p.Source + ": "
+	}
+	if p.Field != "" {
+		prefix += p.Field + ": "
+	}
+
+	return prefix + p.Err.Error()
+}
+
+func (p Problem) Unwrap() error {
+	return p.Err
+}
+
+func (p Problems) Error() string {
+	var lines []string
+	for _, problem := range p {
+		lines = append(lines, problem.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks and compiles the given values without applying any defaults.
+// The source is used as context of the problems, eg the config file name.
+// The values depending on other values, which may come from another source, are checked by Valid.
+func (o *Options) Validate(source string) error {
+	v := &validator{source: source}
+
+	o.validateFields(v)
+	v.versionSyntax("initial_version", "invalid initial version", o.InitialVersion)
+	v.versionSyntax("fake_prerelease", "invalid fake prerelease version", o.FakePrerelease)
+	v.versionSyntax("release_as", "invalid release-as version", o.ReleaseAs)
+
+	return v.err()
+}
+
+// validateFields checks and compiles the values which do not depend on other values
+func (o *Options) validateFields(v *validator) {
+	o.versionScheme = v.scheme("scheme", o.Scheme)
+	v.prerelease("pre", o.Prerelease)
+	o.constraint = v.constraint("constraint", o.Constraint)
+	o.noMatchBump = v.noMatchBump(o.NoMatchBump)
+	v.tagPrefix("tag_prefix", o.TagPrefix)
+	v.preset("extends", o.Extends)
+	v.outputFormat("output_format", o.OutputFormat)
+	v.logFormat("log_format", o.LogFormat)
+
+	v.pathSyntax("path_syntax", o.PathSyntax)
+	v.patterns("path_include", o.PathInclude)
+	v.patterns("path_exclude", o.PathExclude)
+	o.pathMax = v.pathLevels("path_max", o.PathMax)
+	o.pathMin = v.pathLevels("path_min", o.PathMin)
+	v.notNegative("max_commits", o.MaxCommits)
+
+	o.keywordsMajor = v.regexps("keywords_major", o.KeywordsMajor)
+	o.keywordsMinor = v.regexps("keywords_minor", o.KeywordsMinor)
+	o.keywordsPatch = v.regexps("keywords_patch", o.KeywordsPatch)
+}
+
+// validateVersions checks and compiles the versions, which depend on the scheme and the prerelease keyword
+func (o *Options) validateVersions(v *validator) {
+	o.initialVersion = v.version("initial_version", "invalid initial version", o.InitialVersion, o.SchemeValue())
+	v.version("fake_prerelease", "invalid fake prerelease version", o.FakePrerelease, o.SchemeValue())
+	o.releaseAs = v.releaseAs(o)
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+
+	return v.problems
+}
+
+func (v *validator) add(field string, err error) {
+	source := v.source
+	if source == "" {
+		source = v.sources[field]
+	}
+
+	v.problems = append(v.problems, Problem{
+		Source: source,
+		Field:  field,
+		Err:    err,
+	})
+}
+
+func (v *validator) scheme(field, val string) scheme.Scheme {
+	if val == "" {
+		return nil
+	}
+
+	result, err := scheme.New(val)
+	if err != nil {
+		v.add(field, err)
+		return nil
+	}
+
+	return result
+}
+
+func (v *validator) version(field, label, val string, s scheme.Scheme) *semver.Version {
+	if val == "" {
+		return nil
+	}
+
+	result, err := s.Parse(val)
+	if err != nil {
+		v.add(field, fmt.Errorf("%v %v: %w", label, val, err))
+		return nil
+	}
+
+	return result
+}
+
+// versionSyntax checks that the value is a version of any scheme, the scheme itself may be given by another source
+func (v *validator) versionSyntax(field, label, val string) {
+	if val == "" {
+		return
+	}
+
+	if _, err := semver.NewVersion(val); err != nil {
+		v.add(field, fmt.Errorf("%v %v: %w", label, val, err))
+	}
+}
+
+func (v *validator) notNegative(field string, val int) {
+	if val < 0 {
+		v.add(field, fmt.Errorf("invalid value %v: must not be negative", val))
+	}
+}
+
+func (v *validator) prerelease(field, val string) {
+	if val == "" || prereleaseKeyword.MatchString(val) {
+		return
+	}
+
+	v.add(field, fmt.Errorf("invalid prerelease keyword %v", val))
+}
+
+func (v *validator) releaseAs(o *Options) *semver.Version {
+	result := v.version("release_as", "invalid release-as version", o.ReleaseAs, o.SchemeValue())
+	if result == nil {
+		return nil
+	}
+
+	if o.BumpPrerelease() {
+		if !strings.HasPrefix(result.Prerelease(), o.Prerelease+".") {
+			v.add("release_as", fmt.Errorf("release-as version %v is not a %v prerelease", o.ReleaseAs, o.Prerelease))
+			return nil
+		}
+	} else if result.Prerelease() != "" {
+		v.add("release_as", fmt.Errorf("release-as version %v is a prerelease but no prerelease keyword is given", o.ReleaseAs))
+		return nil
+	}
+
+	return result
+}
+
+func (v *validator) constraint(field, val string) *semver.Constraints {
+	if val == "" {
+		return nil
+	}
+
+	result, err := semver.NewConstraint(val)
+	if err != nil {
+		v.add(field, fmt.Errorf("invalid constraint %v: %w", val, err))
+		return nil
+	}
+
+	return result
+}
+
+func (v *validator) noMatchBump(val string) FallbackStrategy {
+	switch val {
+	case "", fallbackStrategyNone:
+		return FallbackStrategyNone
+	case fallbackStrategyPatch:
+		return FallbackStrategyPatch
+	default:
+		v.add("no_match_bump", fmt.Errorf("invalid no match bump value: %v", val))
+		return FallbackStrategyNone
+	}
+}
+
+func (v *validator) outputFormat(field, val string) {
+	switch val {
+	case "", OutputFormatVersion, OutputFormatDotenv, OutputFormatExport, OutputFormatGithub, OutputFormatGitlab:
+	default:
+		v.add(field, fmt.Errorf("invalid output format %v", val))
+	}
+}
+
+func (v *validator) logFormat(field, val string) {
+	switch val {
+	case "", logger.FormatText, logger.FormatJSON:
+	default:
+		v.add(field, fmt.Errorf("invalid log format %v", val))
+	}
+}
+
+func (v *validator) pathSyntax(field, val string) {
+	switch val {
+	case "", PathSyntaxGlob, PathSyntaxGitignore:
+	default:
+		v.add(field, fmt.Errorf("invalid path syntax %v", val))
+	}
+}
+
+func (v *validator) tagPrefix(field, val string) {
+	if strings.ContainsAny(val, invalidTagPrefixCharacters) || strings.Contains(val, "..") || strings.Contains(val, "@{") {
+		v.add(field, fmt.Errorf("invalid tag prefix %q: a git tag cannot contain any of %q, \"..\", or \"@{\"", val, invalidTagPrefixCharacters))
+	}
+}
+
+func (v *validator) preset(field, val string) {
+	if val == "" {
+		return
+	}
+
+	for _, name := range Presets() {
+		if name == val {
+			return
+		}
+	}
+
+	v.add(field, fmt.Errorf("unknown preset %v, available presets are: %v", val, strings.Join(Presets(), ", ")))
+}
+
+func (v *validator) patterns(field string, val []string) {
+	for i, pattern := range val {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			v.add(fmt.Sprintf("%v[%d]", field, i), fmt.Errorf("invalid path pattern %q: %w", pattern, err))
+		}
+	}
+}
+
+func (v *validator) pathLevels(field string, val map[string]string) map[string]BumpLevel {
+	if len(val) == 0 {
+		return nil
+	}
+
+	result := make(map[string]BumpLevel)
+	for pattern, level := range val {
+		entry := fmt.Sprintf("%v[%v]", field, pattern)
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			v.add(entry, fmt.Errorf("invalid path pattern %q: %w", pattern, err))
+			continue
+		}
+
+		lvl, err := ParseBumpLevel(level)
+		if err != nil {
+			v.add(entry, err)
+			continue
+		}
+
+		result[pattern] = lvl
+	}
+
+	return result
+}
+
+func (v *validator) regexps(field string, val []string) []*regexp.Regexp {
+	var result []*regexp.Regexp
+	for i, expr := range val {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			v.add(fmt.Sprintf("%v[%d]", field, i), fmt.Errorf("invalid regular expression %q: %w", expr, err))
+			continue
+		}
+
+		result = append(result, re)
+	}
+
+	return result
+}
@@ -3,51 +3,81 @@ package estimator
 import (
 	"fmt"
 	. "github.com/timotto/semver-bumper/pkg/config"
+	"github.com/timotto/semver-bumper/pkg/logger"
 	. "github.com/timotto/semver-bumper/pkg/model"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
-type estimator struct {
-	config *Options
-}
+type (
+	estimator struct {
+		config   *Options
+		matchers []levelMatcher
+		log      *logger.Logger
+	}
 
+	// levelMatcher holds the keyword expressions of a bump level
+	levelMatcher struct {
+		level   BumpLevel
+		regexps []*regexp.Regexp
+	}
+)
+
+// NewEstimator expects valid options, the keywords are compiled during the validation already.
+// A single alternation per level is slower, it defeats the literal prefix optimization of each keyword.
 func NewEstimator(config *Options) *estimator {
 	return &estimator{
 		config: config,
+		matchers: []levelMatcher{
+			{level: BumpLevelMajor, regexps: config.KeywordsMajorValue()},
+			{level: BumpLevelMinor, regexps: config.KeywordsMinorValue()},
+			{level: BumpLevelPatch, regexps: config.KeywordsPatchValue()},
+		},
 	}
 }
 
-func (e estimator) BumpLevelFrom(commitMessages []string) BumpLevel {
-	lvl := BumpLevelNone
-	switch e.config.NoMatchBumpValue() {
-	case FallbackStrategyPatch:
-		lvl = BumpLevelPatch
-	}
+// WithLogger logs the keyword matches of each commit message
+func (e *estimator) WithLogger(log *logger.Logger) *estimator {
+	e.log = log
+	return e
+}
 
+func (e estimator) BumpLevelFrom(commitMessages []string) BumpLevel {
+	lvl := e.FallbackLevel()
 	for _, message := range commitMessages {
-		if containsAny(message, e.config.KeywordsMajor) {
-			lvl = BumpLevelMajor
+		lvl = lvl.Max(e.CommitBumpLevel(message))
+		if lvl == BumpLevelMajor {
 			break
 		}
+	}
 
-		if containsAny(message, e.config.KeywordsMinor) {
-			if lvl < BumpLevelMinor {
-				lvl = BumpLevelMinor
-			}
-			continue
-		}
+	return lvl
+}
+
+func (e estimator) FallbackLevel() BumpLevel {
+	switch e.config.NoMatchBumpValue() {
+	case FallbackStrategyPatch:
+		return BumpLevelPatch
+	default:
+		return BumpLevelNone
+	}
+}
 
-		if containsAny(message, e.config.KeywordsPatch) {
-			if lvl < BumpLevelMinor {
-				lvl = BumpLevelPatch
+func (e estimator) CommitBumpLevel(commitMessage string) BumpLevel {
+	for _, m := range e.matchers {
+		if re := m.match(commitMessage); re != nil {
+			if e.log.Enabled(logger.LevelDebug) {
+				e.log.Debug("keyword matched", "commit", subject(commitMessage), "bump", m.level, "keyword", re)
 			}
-			continue
+			return m.level
 		}
 	}
 
-	return lvl
+	if e.log.Enabled(logger.LevelDebug) {
+		e.log.Debug("no keyword matched", "commit", subject(commitMessage))
+	}
+	return BumpLevelNone
 }
 
 func (e estimator) NextPrerelease(pre string) (string, error) {
@@ -70,12 +100,17 @@ func (e estimator) NextPrerelease(pre string) (string, error) {
 	return fmt.Sprintf("%s%d", prefix, val+1), nil
 }
 
-func containsAny(msg string, regexps []string) bool {
-	for _, re := range regexps {
-		if regexp.MustCompile(re).MatchString(msg) {
-			return true
+// match returns the first keyword matching the message, nil if none does
+func (m levelMatcher) match(msg string) *regexp.Regexp {
+	for _, re := range m.regexps {
+		if re.MatchString(msg) {
+			return re
 		}
 	}
 
-	return false
+	return nil
+}
+
+func subject(msg string) string {
+	return strings.TrimSpace(strings.SplitN(msg, "\n", 2)[0])
 }
@@ -0,0 +1,32 @@
+package estimator_test
+
+import (
+	"fmt"
+	"github.com/timotto/semver-bumper/pkg/config"
+	. "github.com/timotto/semver-bumper/pkg/estimator"
+	"testing"
+)
+
+func BenchmarkBumpLevelFrom(b *testing.B) {
+	cfg := &config.Options{
+		KeywordsMajor: []string{"^BREAKING CHANGE:", "^[a-z]+!:", `(?m)^BREAKING-CHANGE:`},
+		KeywordsMinor: []string{"^feat:", "^feat\\(", ":sparkles:", "^minor:"},
+		KeywordsPatch: []string{"^fix:", "^fix\\(", "^chore:", "^perf:", "^refactor:", ":bug:", "^patch:"},
+	}
+	if err := cfg.Valid(); err != nil {
+		b.Fatal(err)
+	}
+
+	var messages []string
+	for i := 0; i < 10000; i++ {
+		messages = append(messages, fmt.Sprintf("docs: describe change number %d\n\nwith a longer body", i))
+	}
+	messages = append(messages, "fix: the last commit")
+
+	uut := NewEstimator(cfg)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		uut.BumpLevelFrom(messages)
+	}
+}
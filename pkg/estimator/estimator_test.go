@@ -69,6 +69,29 @@ var _ = Describe("Estimator", func() {
 		})
 	})
 
+	Describe("CommitBumpLevel", func() {
+		DescribeTable(
+			"returns the level of a single commit message",
+			func(message string, expected BumpLevel) {
+				Expect(NewEstimator(aConfiguration()).CommitBumpLevel(message)).To(Equal(expected))
+			},
+			Entry("no match", testNoKeyword, BumpLevelNone),
+			Entry("patch", testPatchKeyword, BumpLevelPatch),
+			Entry("minor", testMinorKeyword, BumpLevelMinor),
+			Entry("major", testMajorKeyword, BumpLevelMajor),
+			Entry("minor & major", testMinorKeyword+" "+testMajorKeyword, BumpLevelMajor),
+		)
+
+		It("ignores the NoMatchBump configuration", func() {
+			cfg := aConfiguration()
+			cfg.NoMatchBump = "patch"
+			Expect(cfg.Valid()).ToNot(HaveOccurred())
+
+			Expect(NewEstimator(cfg).CommitBumpLevel(testNoKeyword)).To(Equal(BumpLevelNone))
+			Expect(NewEstimator(cfg).FallbackLevel()).To(Equal(BumpLevelPatch))
+		})
+	})
+
 	Describe("NextPrerelease", func() {
 		DescribeTable(
 			"behavior",
@@ -2,8 +2,10 @@ package gitrepo
 
 import (
 	"fmt"
-	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/timotto/semver-bumper/pkg/logger"
+	"github.com/timotto/semver-bumper/pkg/scheme"
+	"sort"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
@@ -12,67 +14,78 @@ import (
 
 type (
 	collector struct {
-		strict bool
-		repo   *git.Repository
 		prefix string
-		Result []taggedCommit
+		scheme scheme.Scheme
+		log    *logger.Logger
+		Result collection
 	}
 	taggedCommit struct {
-		Tag *semver.Version
+		Tag  *semver.Version
+		Name string
+		// hash is the target of the tag reference, a commit or an annotated tag object
+		hash plumbing.Hash
+		// Ref is the tagged commit, it is nil until resolved
 		Ref *object.Commit
 	}
-	collection []taggedCommit
+	collection []*taggedCommit
 )
 
-func (g Gitrepo) newCollector(strict bool) *collector {
+func (g Gitrepo) newCollector() *collector {
 	return &collector{
-		strict: strict,
-		repo:   g.repo,
 		prefix: g.conf.TagPrefix,
+		scheme: g.conf.SchemeValue(),
+		log:    g.log,
 	}
 }
 
+// collect parses the version of a tag, the commit is resolved on demand
 func (c *collector) collect(ref *plumbing.Reference) error {
 	ok, tag := c.hasTagPrefix(ref.Name().Short())
 	if !ok {
+		if c.log.Enabled(logger.LevelDebug) {
+			c.log.Debug("tag skipped", "tag", ref.Name().Short(), "reason", "no tag prefix "+c.prefix)
+		}
 		return nil
 	}
 
-	v, err := semver.StrictNewVersion(tag)
+	v, err := c.scheme.Parse(tag)
 	if err != nil {
 		return fmt.Errorf("failed to parse version [%v]: %w", tag, err)
 	}
 
-	if c.strict {
-		if v.Prerelease() != "" || v.Metadata() != "" {
-			return nil
-		}
-	}
-
-	commit, err := c.resolveCommit(ref, v)
-	if err != nil {
-		return fmt.Errorf("failed to resolve commit for %v: %w", tag, err)
-	}
-
-	item := taggedCommit{
-		Tag: v,
-		Ref: commit,
+	if c.log.Enabled(logger.LevelDebug) {
+		c.log.Debug("tag found", "tag", ref.Name().Short(), "version", v)
 	}
-	c.Result = append(c.Result, item)
+	c.Result = append(c.Result, &taggedCommit{
+		Tag:  v,
+		Name: ref.Name().Short(),
+		hash: ref.Hash(),
+	})
 
 	return nil
 }
 
-func (c collector) resolveCommit(ref *plumbing.Reference, v *semver.Version) (*object.Commit, error) {
-	tagObject, err := c.repo.TagObject(ref.Hash())
+// resolve sets the commit of the tag once
+func (g Gitrepo) resolve(t *taggedCommit) (*object.Commit, error) {
+	if t.Ref != nil {
+		return t.Ref, nil
+	}
+
+	tagObject, err := g.repo.TagObject(t.hash)
 	switch err {
 	case plumbing.ErrObjectNotFound:
-		return c.repo.CommitObject(ref.Hash())
+		t.Ref, err = g.repo.CommitObject(t.hash)
 	case nil:
-		return tagObject.Commit()
-	default:
-		return nil, fmt.Errorf("failed to resolve tag %v: %w", v, err)
+		t.Ref, err = tagObject.Commit()
+	}
+	if err == plumbing.ErrObjectNotFound {
+		return nil, fmt.Errorf("the commit %v of tag %v is missing, the repository may be a shallow clone: "+
+			"fetch it with \"git fetch --unshallow --tags\": %w", t.hash, t.Name, err)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to resolve commit for %v: %w", t.Name, err)
 	}
+
+	return t.Ref, nil
 }
 
 func (c collector) hasTagPrefix(tag string) (bool, string) {
@@ -91,22 +104,40 @@ func (t taggedCommit) IsVersion(v *semver.Version) bool {
 	return t.Tag.Equal(v)
 }
 
+// IsCommit expects the tag to be resolved
 func (t taggedCommit) IsCommit(commit *object.Commit) bool {
 	return t.Ref.Hash == commit.Hash
 }
 
-func (n collection) Len() int {
-	return len(n)
+// sortBy sorts the collection by the order of the versioning scheme, the latest version last
+func (n collection) sortBy(s scheme.Scheme) {
+	sort.Slice(n, func(i, j int) bool {
+		return s.Compare(n[i].Tag, n[j].Tag) < 0
+	})
 }
 
-func (n collection) Less(i, j int) bool {
-	return n[i].Tag.LessThan(n[j].Tag)
+func (n collection) Latest() *taggedCommit {
+	return n[len(n)-1]
 }
 
-func (n collection) Swap(i, j int) {
-	n[i], n[j] = n[j], n[i]
+// releases returns the versions without prerelease and metadata
+func (n collection) releases() collection {
+	result := collection{}
+	for _, t := range n {
+		if t.Tag.Prerelease() == "" && t.Tag.Metadata() == "" {
+			result = append(result, t)
+		}
+	}
+
+	return result
 }
 
-func (n collection) Latest() taggedCommit {
-	return n[len(n)-1]
+func (n collection) find(v *semver.Version) *taggedCommit {
+	for _, t := range n {
+		if t.IsVersion(v) {
+			return t
+		}
+	}
+
+	return nil
 }
@@ -6,7 +6,7 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
-	"io"
+	"strings"
 )
 
 func (g Gitrepo) CommitMessagesSince(v *semver.Version) ([]*object.Commit, error) {
@@ -19,26 +19,20 @@ func (g Gitrepo) CommitMessagesSince(v *semver.Version) ([]*object.Commit, error
 		return nil, err
 	}
 
-	for _, tag := range tags {
-		if tag.IsVersion(v) {
-			return g.commitMessagesSince(&tag)
-		}
+	tag := tags.find(v)
+	if tag == nil {
+		return nil, nil
 	}
 
-	return nil, nil
-}
-
-func (g Gitrepo) commitMessagesSince(tag *taggedCommit) ([]*object.Commit, error) {
-	opts := &git.LogOptions{
-		Order:      git.LogOrderCommitterTime,
-		PathFilter: g.FiltersAccept,
+	if _, err := g.resolve(tag); err != nil {
+		return nil, err
 	}
 
-	if tag != nil {
-		opts.Since = &tag.Ref.Committer.When
-	}
+	return g.commitMessagesSince(tag)
+}
 
-	iter, err := g.repo.Log(opts)
+func (g Gitrepo) commitMessagesSince(tag *taggedCommit) ([]*object.Commit, error) {
+	head, err := g.repo.Head()
 	if err != nil {
 		if tag == nil && err == plumbing.ErrReferenceNotFound {
 			// bare / no commits
@@ -48,40 +42,75 @@ func (g Gitrepo) commitMessagesSince(tag *taggedCommit) ([]*object.Commit, error
 		return nil, fmt.Errorf("cannot get log: %w", err)
 	}
 
-	return collectMessages(iter, tag)
-}
+	var until *object.Commit
+	if tag != nil {
+		until = tag.Ref
+		g.log.Info("commit range", "from", head.Hash(), "until", tag.Name, "commit", tag.Ref.Hash)
+	} else {
+		g.log.Info("commit range", "from", head.Hash(), "until", "root")
+	}
 
-type commitMessageCollector struct {
-	stop *taggedCommit
+	c := &commitMessageCollector{}
+	if err := g.walkLog(head.Hash(), until, c.collect); err != nil {
+		return nil, err
+	}
+	g.log.Info("commits since the latest release", "count", len(c.commits))
 
-	commits []*object.Commit
+	return c.commits, nil
 }
 
-func collectMessages(iter object.CommitIter, stop *taggedCommit) ([]*object.Commit, error) {
-	c := &commitMessageCollector{stop: stop}
-	err := c.run(iter)
+// CommitsInRange returns the commits of a range like "v1.2.3..HEAD", the commits reachable from the end
+// but not from the start, or all commits reachable from a single revision. The end defaults to HEAD.
+func (g Gitrepo) CommitsInRange(revisionRange string) ([]*object.Commit, error) {
+	start, end := "", revisionRange
+	if parts := strings.SplitN(revisionRange, "..", 2); len(parts) == 2 {
+		start, end = parts[0], parts[1]
+	}
+	if end == "" {
+		end = "HEAD"
+	}
+
+	excluded := make(map[plumbing.Hash]bool)
+	if start != "" {
+		hash, err := g.repo.ResolveRevision(plumbing.Revision(start))
+		if err != nil {
+			return nil, fmt.Errorf("cannot resolve %v: %w", start, err)
+		}
 
-	return c.commits, err
-}
+		iter, err := g.repo.Log(&git.LogOptions{From: *hash})
+		if err != nil {
+			return nil, fmt.Errorf("cannot get log: %w", err)
+		}
 
-func (c *commitMessageCollector) run(iter object.CommitIter) error {
-	err := iter.ForEach(c.collect)
-	switch err {
-	case io.EOF:
-		fallthrough
-	case nil:
-		return nil
+		if err := iter.ForEach(func(commit *object.Commit) error {
+			excluded[commit.Hash] = true
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
 
-	default:
-		return err
+	hash, err := g.repo.ResolveRevision(plumbing.Revision(end))
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %v: %w", end, err)
 	}
+
+	var result []*object.Commit
+	err = g.walkLog(*hash, nil, func(commit *object.Commit) error {
+		if !excluded[commit.Hash] {
+			result = append(result, commit)
+		}
+		return nil
+	})
+
+	return result, err
 }
 
-func (c *commitMessageCollector) collect(commit *object.Commit) error {
-	if c.stop != nil && c.stop.IsCommit(commit) {
-		return io.EOF
-	}
+type commitMessageCollector struct {
+	commits []*object.Commit
+}
 
+func (c *commitMessageCollector) collect(commit *object.Commit) error {
 	c.commits = append(c.commits, commit)
 
 	return nil
@@ -0,0 +1,81 @@
+package gitrepo
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// FilesAt returns the content of the accepted files in the commit tagged with the
+// given version, or in HEAD if the version is nil.
+func (g Gitrepo) FilesAt(v *semver.Version, accept func(name string) bool) (map[string][]byte, error) {
+	commit, err := g.commitOf(v)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]byte)
+	if commit == nil {
+		return result, nil
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read tree of commit %v: %w", commit.Hash, err)
+	}
+
+	err = tree.Files().ForEach(func(file *object.File) error {
+		if !accept(file.Name) {
+			return nil
+		}
+
+		content, err := file.Contents()
+		if err != nil {
+			return fmt.Errorf("cannot read %v: %w", file.Name, err)
+		}
+
+		result[file.Name] = []byte(content)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (g Gitrepo) commitOf(v *semver.Version) (*object.Commit, error) {
+	if v == nil {
+		return g.headCommit()
+	}
+
+	tags, err := g.versionTags(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag := tags.find(v); tag != nil {
+		return g.resolve(tag)
+	}
+
+	return nil, fmt.Errorf("there is no tag for version %v", v)
+}
+
+func (g Gitrepo) headCommit() (*object.Commit, error) {
+	head, err := g.repo.Head()
+	if err == plumbing.ErrReferenceNotFound {
+		// bare / no commits
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot resolve HEAD: %w", err)
+	}
+
+	commit, err := g.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve HEAD commit: %w", err)
+	}
+
+	return commit, nil
+}
@@ -1,13 +1,33 @@
 package gitrepo
 
 import (
-	"fmt"
-	"os"
+	"github.com/timotto/semver-bumper/pkg/logger"
 	"path"
 	"path/filepath"
+	"strings"
 )
 
+// FiltersAccept is true if the file matches the path include and exclude patterns. With the glob syntax
+// a pattern matches the file or any of its parent directories. With --scope-to-dir the file must also be
+// inside the directory the repository was opened in.
 func (g Gitrepo) FiltersAccept(filename string) bool {
+	accepted := g.filtersAccept(filename)
+	if g.log.Enabled(logger.LevelDebug) {
+		g.log.Debug("path filter", "file", filename, "accepted", accepted)
+	}
+
+	return accepted
+}
+
+func (g Gitrepo) filtersAccept(filename string) bool {
+	if !g.inScope(filename) {
+		return false
+	}
+
+	if g.patterns != nil {
+		return g.patterns.accept(filename)
+	}
+
 	names := allTheWayDown(filename)
 	accepted := false
 
@@ -24,6 +44,46 @@ func (g Gitrepo) FiltersAccept(filename string) bool {
 	return accepted
 }
 
+// dirRejected is true if the path filters reject every file in the directory
+func (g Gitrepo) dirRejected(name string) bool {
+	rejected := g.excludeRejects(name)
+	if g.patterns != nil {
+		rejected = g.patterns.rejectsDir(name)
+	}
+
+	// a directory outside the scope is rejected unless it contains the scope
+	if !g.inScope(name) && !strings.HasPrefix(g.scope()+"/", name+"/") {
+		rejected = true
+	}
+
+	if rejected && g.log.Enabled(logger.LevelDebug) {
+		g.log.Debug("path filter", "dir", name, "accepted", false)
+	}
+
+	return rejected
+}
+
+// hasPathFilters is false if every file is accepted
+func (g Gitrepo) hasPathFilters() bool {
+	return len(g.conf.PathInclude) > 0 || len(g.conf.PathExclude) > 0 || g.scope() != ""
+}
+
+// scope is the directory the repository was opened in if the path filters are restricted to it, empty otherwise
+func (g Gitrepo) scope() string {
+	if !g.conf.ShouldScopeToDir() {
+		return ""
+	}
+
+	return g.dir
+}
+
+// inScope is true if the file or directory is inside the scope
+func (g Gitrepo) inScope(name string) bool {
+	scope := g.scope()
+
+	return scope == "" || name == scope || strings.HasPrefix(name, scope+"/")
+}
+
 func (g Gitrepo) includeAccepts(name string) bool {
 	if len(g.conf.PathInclude) == 0 {
 		return true
@@ -31,7 +91,7 @@ func (g Gitrepo) includeAccepts(name string) bool {
 
 	for _, pattern := range g.conf.PathInclude {
 		if match, err := filepath.Match(pattern, name); err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "include filter pattern %v with name %v returns an error: %v\n", pattern, name, err.Error())
+			g.log.Warn("invalid include path pattern", "pattern", pattern, "file", name, "err", err)
 			return false
 		} else if match {
 			return true
@@ -48,7 +108,7 @@ func (g Gitrepo) excludeRejects(name string) bool {
 
 	for _, pattern := range g.conf.PathExclude {
 		if match, err := filepath.Match(pattern, name); err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "exclude filter pattern %v with name %v returns an error: %v\n", pattern, name, err.Error())
+			g.log.Warn("invalid exclude path pattern", "pattern", pattern, "file", name, "err", err)
 			return true
 		} else if match {
 			return true
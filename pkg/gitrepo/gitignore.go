@@ -0,0 +1,65 @@
+package gitrepo
+
+import (
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	"strings"
+)
+
+// gitignorePatterns are the path include and exclude lists in a gitignore-like syntax,
+// within a list the last matching pattern decides, so "!" patterns re-include paths.
+// Unlike git, each file is matched on its own, so a file below an excluded directory can be re-included.
+type gitignorePatterns struct {
+	include, exclude gitignore.Matcher
+	// reinclude is true if a directory matching the exclude list may still contain accepted files
+	reinclude bool
+}
+
+// newGitignorePatterns is nil unless the path syntax is gitignore
+func newGitignorePatterns(conf *Options) *gitignorePatterns {
+	if conf.PathSyntaxValue() != PathSyntaxGitignore {
+		return nil
+	}
+
+	return &gitignorePatterns{
+		include:   newMatcher(conf.PathInclude),
+		exclude:   newMatcher(conf.PathExclude),
+		reinclude: hasNegation(conf.PathExclude),
+	}
+}
+
+func (p *gitignorePatterns) accept(name string) bool {
+	path := strings.Split(name, "/")
+	if p.include != nil && !p.include.Match(path, false) {
+		return false
+	}
+
+	return p.exclude == nil || !p.exclude.Match(path, false)
+}
+
+func (p *gitignorePatterns) rejectsDir(name string) bool {
+	return p.exclude != nil && !p.reinclude && p.exclude.Match(strings.Split(name, "/"), true)
+}
+
+func newMatcher(patterns []string) gitignore.Matcher {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	var result []gitignore.Pattern
+	for _, pattern := range patterns {
+		result = append(result, gitignore.ParsePattern(pattern, nil))
+	}
+
+	return gitignore.NewMatcher(result)
+}
+
+func hasNegation(patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,31 +1,115 @@
 package gitrepo
 
 import (
+	"context"
 	"fmt"
 	. "github.com/timotto/semver-bumper/pkg/config"
-	"sort"
+	"github.com/timotto/semver-bumper/pkg/logger"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"path/filepath"
 )
 
-type Gitrepo struct {
-	conf *Options
-	repo *git.Repository
-}
+type (
+	Gitrepo struct {
+		conf     *Options
+		repo     *git.Repository
+		index    *tagIndex
+		patterns *gitignorePatterns
+		log      *logger.Logger
+		// dir is the directory the repository was opened in, relative to the root of the worktree
+		dir string
+		ctx context.Context
+	}
+
+	// TaggedVersion is a version tag and the commit it points to
+	TaggedVersion struct {
+		Version *semver.Version
+		Tag     string
+		Commit  *object.Commit
+	}
+)
 
+// NewGitRepo opens the repository at the path or at any of its parents, including worktrees
 func NewGitRepo(conf *Options, path string) (*Gitrepo, error) {
 	var err error
 
-	r := &Gitrepo{conf: conf}
-	r.repo, err = git.PlainOpen(path)
+	r := &Gitrepo{conf: conf, index: &tagIndex{}, patterns: newGitignorePatterns(conf)}
+	r.repo, err = git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
 	if err != nil {
 		return nil, fmt.Errorf("cannot open git: %w", err)
 	}
 
+	r.dir, err = worktreeDir(r.repo, path)
+	if err != nil {
+		return nil, err
+	}
+
 	return r, nil
 }
 
+// worktreeDir returns the path relative to the root of the worktree in slash notation,
+// empty for the root and for a bare repository
+func worktreeDir(repo *git.Repository, path string) (string, error) {
+	worktree, err := repo.Worktree()
+	if err == git.ErrIsBareRepository {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("cannot get worktree: %w", err)
+	}
+
+	root, err := filepath.EvalSymlinks(worktree.Filesystem.Root())
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve worktree root: %w", err)
+	}
+
+	dir, err := filepath.Abs(path)
+	if err == nil {
+		dir, err = filepath.EvalSymlinks(dir)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %v: %w", path, err)
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %v in worktree %v: %w", path, root, err)
+	}
+
+	if rel == "." {
+		return "", nil
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+// WithContext stops reading the tags and walking the history once the context is done
+func (g *Gitrepo) WithContext(ctx context.Context) *Gitrepo {
+	g.ctx = ctx
+	return g
+}
+
+// canceled is the error of the context once it is done, nil without a context
+func (g Gitrepo) canceled() error {
+	if g.ctx == nil {
+		return nil
+	}
+
+	return g.ctx.Err()
+}
+
+// WithLogger logs the tags found or skipped, the commit range, and the path filter decisions
+func (g *Gitrepo) WithLogger(log *logger.Logger) *Gitrepo {
+	g.log = log
+	if scope := g.scope(); scope != "" {
+		g.log.Info("path scope", "dir", scope)
+	}
+	return g
+}
+
 func (g Gitrepo) LatestTaggedRelease() (*semver.Version, error) {
 	versions, err := g.versionTags(true)
 	if err != nil {
@@ -52,18 +136,97 @@ func (g Gitrepo) LatestTaggedPrerelease() (*semver.Version, error) {
 	return versions.Latest().Tag, nil
 }
 
-func (g Gitrepo) versionTags(strict bool) (collection, error) {
-	iter, err := g.repo.Tags()
+// Versions returns all releases and prereleases tagged with the tag prefix, the latest one last
+func (g Gitrepo) Versions() ([]TaggedVersion, error) {
+	versions, err := g.versionTags(false)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []TaggedVersion
+	for _, v := range versions {
+		commit, err := g.resolve(v)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, TaggedVersion{Version: v.Tag, Tag: v.Name, Commit: commit})
+	}
+
+	return result, nil
+}
+
+// CreateTag creates a lightweight tag for the given version at HEAD and returns its name
+func (g Gitrepo) CreateTag(v *semver.Version) (string, error) {
+	head, err := g.repo.Head()
 	if err != nil {
-		return nil, fmt.Errorf("cannot list git tags: %w", err)
+		return "", fmt.Errorf("cannot resolve HEAD: %w", err)
 	}
 
-	c := g.newCollector(strict)
-	if err := iter.ForEach(c.collect); err != nil {
+	return g.createTag(v, head.Hash())
+}
+
+// CreateTagAt creates a lightweight tag for the given version at the given commit and returns its name
+func (g Gitrepo) CreateTagAt(v *semver.Version, commit *object.Commit) (string, error) {
+	return g.createTag(v, commit.Hash)
+}
+
+// CreateTagsAt creates lightweight tags for the versions at their commits, either all or none of them.
+// It fails before creating any tag if a version or a tag name exists already, and deletes the tags
+// it created if creating one fails. The result has the tag names.
+func (g Gitrepo) CreateTagsAt(versions []TaggedVersion) ([]TaggedVersion, error) {
+	existing, err := g.versionTags(false)
+	if err != nil {
 		return nil, err
 	}
 
-	sort.Sort(collection(c.Result))
+	names := make(map[string]bool)
+	for _, v := range versions {
+		name := g.conf.TagPrefix + g.conf.SchemeValue().String(v.Version)
+		if tag := existing.find(v.Version); tag != nil {
+			return nil, fmt.Errorf("version %v is tagged already as %v", g.conf.SchemeValue().String(v.Version), tag.Name)
+		}
+
+		if _, err := g.repo.Tag(name); err == nil || names[name] {
+			return nil, fmt.Errorf("tag %v exists already", name)
+		} else if err != git.ErrTagNotFound {
+			return nil, fmt.Errorf("cannot check tag %v: %w", name, err)
+		}
+		names[name] = true
+	}
+
+	var result []TaggedVersion
+	for _, v := range versions {
+		name, err := g.createTag(v.Version, v.Commit.Hash)
+		if err != nil {
+			return nil, g.deleteTags(result, err)
+		}
+
+		result = append(result, TaggedVersion{Version: v.Version, Tag: name, Commit: v.Commit})
+	}
+
+	return result, nil
+}
+
+// deleteTags removes the tags created before the cause of the failure
+func (g Gitrepo) deleteTags(created []TaggedVersion, cause error) error {
+	defer g.invalidate()
+
+	for _, v := range created {
+		if err := g.repo.DeleteTag(v.Tag); err != nil {
+			return fmt.Errorf("%w, and cannot delete the created tag %v: %v", cause, v.Tag, err)
+		}
+	}
+
+	return cause
+}
+
+func (g Gitrepo) createTag(v *semver.Version, hash plumbing.Hash) (string, error) {
+	name := g.conf.TagPrefix + g.conf.SchemeValue().String(v)
+	if _, err := g.repo.CreateTag(name, hash, nil); err != nil {
+		return "", fmt.Errorf("cannot create tag %v: %w", name, err)
+	}
+	g.invalidate()
 
-	return c.Result, nil
+	return name, nil
 }
@@ -0,0 +1,99 @@
+package gitrepo_test
+
+import (
+	"flag"
+	"fmt"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	. "github.com/timotto/semver-bumper/pkg/gitrepo"
+	. "github.com/timotto/semver-bumper/pkg/test/git_testbed"
+	"os"
+	"testing"
+)
+
+// go test ./pkg/gitrepo -run '^$' -bench . -args -bench.commits 200000
+var (
+	benchCommits  = flag.Int("bench.commits", 10000, "number of commits in the generated benchmark repository")
+	benchTagEvery = flag.Int("bench.tag-every", 10, "number of commits between two tags in the generated benchmark repository")
+)
+
+func BenchmarkRun(b *testing.B) {
+	benchmarkRun(b, aBenchmarkConfig(b))
+}
+
+func BenchmarkRunWithPathFilter(b *testing.B) {
+	benchmarkRun(b, aBenchmarkConfig(b, withExcludeFilters("docs")))
+}
+
+func benchmarkRun(b *testing.B, conf *Options) {
+	bed := aGeneratedRepo(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		uut, err := NewGitRepo(conf, bed.Path())
+		Expect(err).ToNot(HaveOccurred())
+
+		latest, err := uut.LatestTaggedRelease()
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = uut.LatestTaggedPrerelease()
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = uut.CommitMessagesSince(latest)
+		Expect(err).ToNot(HaveOccurred())
+	}
+}
+
+func BenchmarkVersions(b *testing.B) {
+	bed := aGeneratedRepo(b)
+	conf := aBenchmarkConfig(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		uut, err := NewGitRepo(conf, bed.Path())
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = uut.Versions()
+		Expect(err).ToNot(HaveOccurred())
+	}
+}
+
+// aGeneratedRepo returns a repository with a release tag every few commits, every other one preceded by a
+// prerelease tag, and a few untagged commits on top. The commits change nested files in the source and the docs
+// directories in turn.
+func aGeneratedRepo(b *testing.B) *TestbedRepo {
+	RegisterTestingT(b)
+
+	bed := NewTestbedRepo(os.TempDir())
+	b.Cleanup(bed.Teardown)
+
+	messages := []string{"fix: a bug", "feat: a feature", "chore: some work", "docs: an update"}
+	filenames := []string{"src/pkg/lib/lib.go", "src/cmd/main.go", "docs/guide/index.md", "docs/api/reference.md"}
+	releases := 0
+	bed.AddGeneratedCommitsAt(*benchCommits, messages, filenames, func(i int) string {
+		switch {
+		case i >= *benchCommits-*benchTagEvery/2:
+			return ""
+		case i%*benchTagEvery == *benchTagEvery-1:
+			releases++
+			return fmt.Sprintf("v%d.0.0", releases)
+		case i%(2**benchTagEvery) == *benchTagEvery/2:
+			return fmt.Sprintf("v%d.0.0-rc.1", releases+1)
+		}
+		return ""
+	})
+
+	return bed
+}
+
+func aBenchmarkConfig(b *testing.B, with ...func(configuration *Options)) *Options {
+	conf := &Options{TagPrefix: "v"}
+	for _, fn := range with {
+		fn(conf)
+	}
+	if err := conf.Valid(); err != nil {
+		b.Fatal(err)
+	}
+
+	return conf
+}
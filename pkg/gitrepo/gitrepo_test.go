@@ -1,16 +1,21 @@
 package gitrepo_test
 
 import (
+	"context"
 	"fmt"
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/types"
 	. "github.com/timotto/semver-bumper/pkg/config"
 	. "github.com/timotto/semver-bumper/pkg/gitrepo"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	. "github.com/timotto/semver-bumper/pkg/test/cli_testbed"
 	. "github.com/timotto/semver-bumper/pkg/test/git_testbed"
 	"os"
+	"path/filepath"
 )
 
 var _ = Describe("Gitrepo", func() {
@@ -255,6 +260,233 @@ var _ = Describe("Gitrepo", func() {
 			})
 		})
 	})
+	Describe("Versions", func() {
+		It("returns all version tags with the tag prefix in ascending order", func() {
+			cfg := aConfig()
+			cfg.TagPrefix = "v"
+			Expect(cfg.Valid()).ToNot(HaveOccurred())
+			uut, err := NewGitRepo(cfg, bed.Path())
+			Expect(err).ToNot(HaveOccurred())
+			bed.
+				AddCommits("one").
+				AddLightweightTag("v1.2.3").
+				AddLightweightTag("0.0.1").
+				AddCommits("two").
+				AddAnnotatedTag("v1.3.0-rc.1").
+				AddCommits("three").
+				AddLightweightTag("v1.2.4")
+
+			actual, err := uut.Versions()
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actual).To(HaveLen(3))
+			var tags []string
+			for _, v := range actual {
+				tags = append(tags, v.Tag)
+			}
+			Expect(tags).To(Equal([]string{"v1.2.3", "v1.2.4", "v1.3.0-rc.1"}))
+			Expect(actual[2].Version.String()).To(Equal("1.3.0-rc.1"))
+			Expect(actual[2].Commit.Message).To(Equal("two"))
+		})
+	})
+	Describe("CommitsInRange", func() {
+		BeforeEach(func() {
+			bed.
+				AddCommits("one").
+				AddAnnotatedTag("1.0.0").
+				AddCommits("two").
+				AddLightweightTag("1.1.0").
+				AddCommits("three", "four")
+		})
+		var messages = func(revisionRange string) []string {
+			commits, err := uut.CommitsInRange(revisionRange)
+			Expect(err).ToNot(HaveOccurred())
+
+			var result []string
+			for _, commit := range commits {
+				result = append(result, commit.Message)
+			}
+			return result
+		}
+
+		It("returns the commits after the start up to the end", func() {
+			Expect(messages("1.0.0..1.1.0")).To(Equal([]string{"two"}))
+		})
+
+		It("defaults the end to HEAD", func() {
+			Expect(messages("1.1.0..")).To(Equal([]string{"four", "three"}))
+		})
+
+		It("returns all commits reachable from a single revision", func() {
+			Expect(messages("1.1.0")).To(Equal([]string{"two", "one"}))
+		})
+
+		It("fails for unknown revisions", func() {
+			_, err := uut.CommitsInRange("unknown..HEAD")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("CreateTag", func() {
+		It("tags HEAD with the tag prefix and the version", func() {
+			cfg := aConfig()
+			cfg.TagPrefix = "v"
+			Expect(cfg.Valid()).ToNot(HaveOccurred())
+			uut, err := NewGitRepo(cfg, bed.Path())
+			Expect(err).ToNot(HaveOccurred())
+			bed.AddCommits("one", "two")
+
+			name, err := uut.CreateTag(semver.MustParse("1.2.3"))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal("v1.2.3"))
+			Expect(uut.LatestTaggedRelease()).To(Equal(semver.MustParse("1.2.3")))
+		})
+
+		It("fails if the tag exists", func() {
+			bed.AddCommits("one").AddLightweightTag("1.2.3")
+
+			_, err := uut.CreateTag(semver.MustParse("1.2.3"))
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("CreateTagAt", func() {
+		It("tags the given commit", func() {
+			bed.AddCommits("one", "two")
+
+			name, err := uut.CreateTagAt(semver.MustParse("1.2.3"), bed.Commits()[1])
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal("1.2.3"))
+			commits, err := uut.CommitMessagesSince(semver.MustParse("1.2.3"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(messagesFrom(commits...)).To(Equal([]string{"two"}))
+		})
+	})
+	Describe("WithContext", func() {
+		var cancel context.CancelFunc
+		BeforeEach(func() {
+			bed.
+				AddCommits("one").
+				AddLightweightTag("1.0.0").
+				AddCommits("two", "three")
+
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			uut.WithContext(ctx)
+		})
+
+		It("stops walking the history once the context is done", func() {
+			_, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+			Expect(err).ToNot(HaveOccurred())
+
+			cancel()
+			_, err = uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+			Expect(err).To(MatchError(context.Canceled))
+		})
+
+		It("stops reading the tags once the context is done", func() {
+			cancel()
+			_, err := uut.LatestTaggedRelease()
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+
+	Describe("CreateTagsAt", func() {
+		var planned []TaggedVersion
+		BeforeEach(func() {
+			bed.AddCommits("one", "two", "three")
+			commits := bed.Commits()
+			planned = []TaggedVersion{
+				{Version: semver.MustParse("1.0.0"), Commit: commits[2]},
+				{Version: semver.MustParse("1.1.0"), Commit: commits[1]},
+			}
+		})
+
+		It("tags all given commits", func() {
+			created, err := uut.CreateTagsAt(planned)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(created).To(HaveLen(2))
+			Expect(created[1].Tag).To(Equal("1.1.0"))
+
+			versions, err := uut.Versions()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(HaveLen(2))
+			Expect(versions[1].Commit.Hash).To(Equal(planned[1].Commit.Hash))
+		})
+
+		It("creates no tag if a version is tagged already", func() {
+			bed.AddLightweightTag("1.1.0")
+
+			_, err := uut.CreateTagsAt(planned)
+			Expect(err).To(MatchError(ContainSubstring("version 1.1.0 is tagged already as 1.1.0")))
+
+			versions, err := uut.Versions()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(HaveLen(1))
+		})
+	})
+	Describe("History", func() {
+		BeforeEach(func() {
+			bed.
+				AddCommits("one").
+				AddMerge("merge-1", "branch-1", "branch-2").
+				AddCommitAt("docs/readme.md", "three").
+				AddMerge("merge-2", "branch-3")
+		})
+		var messagesOf = func(commits []*object.Commit, err error) []string {
+			Expect(err).ToNot(HaveOccurred())
+			return messagesFrom(commits...)
+		}
+
+		It("returns the first parent chain of HEAD, the oldest first", func() {
+			history, err := uut.NewHistory(nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(messagesOf(history.FirstParents())).To(Equal([]string{"one", "merge-1", "three", "merge-2"}))
+		})
+
+		It("returns the commits of each step once", func() {
+			history, err := uut.NewHistory(nil)
+			Expect(err).ToNot(HaveOccurred())
+			firstParents, err := history.FirstParents()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(messagesOf(history.CommitsUntil(firstParents[1]))).To(ConsistOf("one", "merge-1", "branch-1", "branch-2"))
+			Expect(messagesOf(history.CommitsUntil(firstParents[1]))).To(BeEmpty())
+			Expect(messagesOf(history.CommitsUntil(firstParents[3]))).To(ConsistOf("three", "merge-2", "branch-3"))
+		})
+
+		It("starts after the given commit", func() {
+			since, err := uut.CommitOf("HEAD~2")
+			Expect(err).ToNot(HaveOccurred())
+			history, err := uut.NewHistory(since)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(messagesOf(history.FirstParents())).To(Equal([]string{"three", "merge-2"}))
+			Expect(messagesOf(history.CommitsUntil(bed.Commits()[0]))).To(ConsistOf("three", "merge-2", "branch-3"))
+		})
+
+		It("fails if the given commit is not on the first parent chain", func() {
+			since, err := uut.CommitOf("HEAD~2^2")
+			Expect(err).ToNot(HaveOccurred())
+			history, err := uut.NewHistory(since)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = history.FirstParents()
+			Expect(err).To(HaveOccurred())
+		})
+
+		When("there is a path filter", func() {
+			BeforeEach(aUnitUnderTest(withIncludeFilters("docs")))
+			It("returns only the commits changing accepted files", func() {
+				history, err := uut.NewHistory(nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(messagesOf(history.CommitsUntil(bed.Commits()[0]))).To(ConsistOf("three"))
+			})
+		})
+	})
 	Describe("CommitMessagesSince", func() {
 		It("returns the commit messages that happened after the commit with the given version", func() {
 			// setup
@@ -371,6 +603,27 @@ var _ = Describe("Gitrepo", func() {
 						expectCommits(1, 2, 6, 7, 8))
 				})
 			})
+
+			When("the path syntax is gitignore", func() {
+				var withGitignoreSyntax = func(p *Options) {
+					p.PathSyntax = PathSyntaxGitignore
+				}
+
+				DescribeTable("matches like a gitignore file",
+					func(include, exclude []string, index ...int) {
+						aUnitUnderTest(withGitignoreSyntax, withIncludeFilters(include...), withExcludeFilters(exclude...))()
+						expectCommits(index...)()
+					},
+					Entry("a name at any depth", []string{"first"}, nil, 3, 4, 5, 7),
+					Entry("an anchored name", []string{"/first"}, nil, 3, 4, 5),
+					Entry("a file name", []string{"*-1"}, nil, 1, 3, 5, 6),
+					Entry("a double asterisk", []string{"**/second-1"}, nil, 5),
+					Entry("everything below a directory", []string{"first/**"}, nil, 3, 4, 5),
+					Entry("an excluded directory", nil, []string{"first/"}, 1, 2, 6, 8),
+					Entry("a re-included directory", nil, []string{"first/", "!first/second/"}, 1, 2, 5, 6, 8),
+					Entry("a re-included file", []string{"*", "!root-*"}, nil, 3, 4, 5, 6, 7, 8),
+				)
+			})
 		})
 
 		When("there are no commits", func() {
@@ -411,6 +664,415 @@ var _ = Describe("Gitrepo", func() {
 				Expect(messagesFrom(actualCommits...)).To(ConsistOf("one", "two", "three", "four"))
 			})
 		})
+
+		When("a merge takes the changes of a branch", func() {
+			BeforeEach(func() {
+				bed.
+					AddCommits("one").
+					AddLightweightTag("1.0.0").
+					AddMerge("merge", "branch-1", "branch-2")
+			})
+
+			It("returns the commits of the branch and the merge", func() {
+				actualCommits, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(ConsistOf("branch-1", "branch-2", "merge"))
+			})
+
+			It("skips the merge when there are path filters", func() {
+				aUnitUnderTest(withExcludeFilters("docs"))()
+				actualCommits, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(ConsistOf("branch-1", "branch-2"))
+			})
+		})
+
+		When("a merge has a breaking change", func() {
+			BeforeEach(func() {
+				bed.
+					AddCommits("one").
+					AddLightweightTag("1.0.0").
+					AddMerge("BREAKING CHANGE: merged", "fix: branch")
+			})
+
+			It("returns the merge", func() {
+				actualCommits, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(ContainElement("BREAKING CHANGE: merged"))
+			})
+		})
+
+		When("the commits have the same timestamp", func() {
+			BeforeEach(func() {
+				bed.
+					WithFrozenTime().
+					AddCommits("one", "two").
+					AddLightweightTag("1.0.0").
+					AddCommits("three", "four")
+			})
+
+			It("returns the commits after the tag", func() {
+				actualCommits, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(ConsistOf("three", "four"))
+			})
+		})
+
+		When("there is a limit of commits", func() {
+			BeforeEach(func() {
+				bed.
+					AddCommits("one").
+					AddLightweightTag("1.0.0").
+					AddCommits("two", "three", "four")
+			})
+
+			It("fails when the walk visits more commits", func() {
+				aUnitUnderTest(withMaxCommits(2))()
+				_, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+				Expect(err).To(MatchError(ContainSubstring("more than 2 commits")))
+			})
+
+			It("returns the commits within the limit", func() {
+				aUnitUnderTest(withMaxCommits(4))()
+				actualCommits, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(ConsistOf("two", "three", "four"))
+			})
+		})
+
+		When("the repository has a commit-graph file", func() {
+			BeforeEach(func() {
+				bed.
+					AddCommitAt("docs/one", "one").
+					AddLightweightTag("1.0.0").
+					AddCommitAt("src/two", "two").
+					AddCommitAt("docs/three", "three")
+				RunCommand("git", "-C", bed.Path(), "commit-graph", "write", "--reachable").ExpectSuccess()
+				bed.AddCommitAt("src/four", "four")
+			})
+			BeforeEach(aUnitUnderTest(withCommitGraph, withExcludeFilters("docs")))
+
+			It("returns the same commits, including the ones missing in the commit-graph", func() {
+				actualCommits, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(Equal([]string{"four", "two"}))
+			})
+		})
+
+		When("the repository is a shallow clone", func() {
+			var clone string
+			BeforeEach(func() {
+				bed.
+					AddCommits("one", "two").
+					AddLightweightTag("1.0.0").
+					AddCommits("three", "four")
+
+				var err error
+				clone, err = os.MkdirTemp(os.TempDir(), "shallow-clone-")
+				Expect(err).ToNot(HaveOccurred())
+			})
+			AfterEach(func() {
+				Expect(os.RemoveAll(clone)).To(Succeed())
+			})
+			var aShallowClone = func(depth int, with ...func(configuration *Options)) {
+				RunCommand("git", "clone", "--quiet", "--depth", fmt.Sprint(depth), "file://"+bed.Path(), clone).ExpectSuccess()
+
+				var err error
+				uut, err = NewGitRepo(aConfig(with...), clone)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			It("fails with a hint to fetch the full history", func() {
+				aShallowClone(3)
+				_, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+				Expect(err).To(MatchError(ErrShallowClone))
+				Expect(err).To(MatchError(ContainSubstring("git fetch --unshallow --tags")))
+			})
+
+			It("returns the commits if allowed and the history since the release tag is present", func() {
+				aShallowClone(3, withAllowShallow)
+				actualResult, err := uut.LatestTaggedRelease()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actualResult.String()).To(Equal("1.0.0"))
+
+				actualCommits, err := uut.CommitMessagesSince(actualResult)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(Equal([]string{"four", "three"}))
+			})
+
+			It("fails if allowed but the clone has no release tag", func() {
+				aShallowClone(2, withAllowShallow)
+				actualResult, err := uut.LatestTaggedRelease()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actualResult).To(BeNil())
+
+				_, err = uut.CommitMessagesSince(nil)
+				Expect(err).To(MatchError(ErrShallowClone))
+				Expect(err).To(MatchError(ContainSubstring("without a release tag")))
+			})
+
+			It("fails if allowed but the commit of the release tag is missing", func() {
+				tagged := bed.Commits()[2].Hash.String()
+				aShallowClone(2, withAllowShallow)
+				Expect(os.WriteFile(filepath.Join(clone, ".git", "refs", "tags", "1.0.0"), []byte(tagged+"\n"), 0644)).To(Succeed())
+
+				_, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+				Expect(err).To(MatchError(ContainSubstring("the commit %v of tag 1.0.0 is missing", tagged)))
+			})
+
+			It("fails to replay the history with a hint to fetch the full history", func() {
+				aShallowClone(3)
+				_, err := uut.NewHistory(nil)
+				Expect(err).To(MatchError(ErrShallowClone))
+				Expect(err).To(MatchError(ContainSubstring("git fetch --unshallow --tags")))
+			})
+
+			It("fails to replay the history from the root commit if allowed", func() {
+				aShallowClone(3, withAllowShallow)
+				history, err := uut.NewHistory(nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = history.FirstParents()
+				Expect(err).To(MatchError(ErrShallowClone))
+				Expect(err).To(MatchError(ContainSubstring("the full history is required")))
+			})
+
+			It("replays the history after a commit if allowed and the history since then is present", func() {
+				aShallowClone(3, withAllowShallow)
+				since, err := uut.CommitOf("1.0.0")
+				Expect(err).ToNot(HaveOccurred())
+				history, err := uut.NewHistory(since)
+				Expect(err).ToNot(HaveOccurred())
+
+				firstParents, err := history.FirstParents()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(firstParents...)).To(Equal([]string{"three", "four"}))
+
+				actualCommits, err := history.CommitsUntil(firstParents[1])
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(ConsistOf("three", "four"))
+			})
+		})
+	})
+
+	Describe("LevelLimits", func() {
+		var expectLimits = func(floor, cap BumpLevel) {
+			head := bed.Commits()[0]
+			actualResult, err := uut.LevelLimits(head)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actualResult).To(Equal(LevelLimits{Floor: floor, Cap: cap}))
+		}
+		BeforeEach(aUnitUnderTest(
+			withPathMax("docs/**", "patch"),
+			withPathMax("examples", "minor"),
+			withPathMin("api/proto/**", "minor"),
+		))
+		BeforeEach(func() {
+			bed.AddCommitAt("main.go", "commit-0")
+		})
+
+		When("there are no path rules", func() {
+			BeforeEach(aUnitUnderTest())
+			BeforeEach(func() {
+				bed.AddCommitAt("docs/readme.md", "commit-1")
+			})
+			It("does not limit the level", func() {
+				expectLimits(BumpLevelNone, BumpLevelMajor)
+			})
+		})
+
+		When("the commit changes only files matching a path max rule", func() {
+			BeforeEach(func() {
+				bed.AddCommitAt("docs/guide/readme.md", "commit-1")
+			})
+			It("caps the level", func() {
+				expectLimits(BumpLevelNone, BumpLevelPatch)
+			})
+		})
+
+		When("the commit changes files matching different path max rules", func() {
+			BeforeEach(func() {
+				bed.AddCommitsAt("commit-1", "docs/readme.md", "examples/main.go")
+			})
+			It("caps the level at the highest of those levels", func() {
+				expectLimits(BumpLevelNone, BumpLevelMinor)
+			})
+		})
+
+		When("the commit also changes files outside the path max rules", func() {
+			BeforeEach(func() {
+				bed.AddCommitsAt("commit-1", "docs/readme.md", "other.go")
+			})
+			It("does not cap the level", func() {
+				expectLimits(BumpLevelNone, BumpLevelMajor)
+			})
+		})
+
+		When("the commit changes a file matching a path min rule", func() {
+			BeforeEach(func() {
+				bed.AddCommitsAt("commit-1", "api/proto/service.proto", "other.go")
+			})
+			It("raises the level", func() {
+				expectLimits(BumpLevelMinor, BumpLevelMajor)
+			})
+		})
+
+		When("the commit merges a branch", func() {
+			BeforeEach(aUnitUnderTest(withPathMin("some-file-*", "minor")))
+			BeforeEach(func() {
+				bed.AddMerge("merge", "branch-1")
+			})
+			It("does not count the files of the branch", func() {
+				expectLimits(BumpLevelNone, BumpLevelMajor)
+			})
+		})
+
+		When("a pattern matches the file name", func() {
+			BeforeEach(aUnitUnderTest(withPathMax("docs/*/*.md", "patch")))
+			BeforeEach(func() {
+				bed.AddCommitsAt("commit-1", "docs/guide/readme.md", "docs/guide/index.md")
+			})
+			It("caps the level", func() {
+				expectLimits(BumpLevelNone, BumpLevelPatch)
+			})
+		})
+	})
+
+	Describe("LevelLimits of the first commit", func() {
+		BeforeEach(aUnitUnderTest(withPathMax("docs", "patch")))
+		BeforeEach(func() {
+			bed.AddCommitAt("docs/readme.md", "commit-1")
+		})
+		It("compares with an empty tree", func() {
+			actualResult, err := uut.LevelLimits(bed.Commits()[0])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actualResult).To(Equal(LevelLimits{Floor: BumpLevelNone, Cap: BumpLevelPatch}))
+		})
+	})
+
+	Describe("NewGitRepo", func() {
+		BeforeEach(func() {
+			bed.
+				AddCommitAt("services/api/one", "one").
+				AddLightweightTag("1.0.0").
+				AddCommitAt("services/api/two", "two").
+				AddCommitAt("services/web/three", "three").
+				AddCommitAt("services/api-docs/four", "four").
+				AddCommitAt("README.md", "five")
+		})
+
+		When("the path is a subdirectory of the repository", func() {
+			var subdir string
+			BeforeEach(func() {
+				subdir = filepath.Join(bed.Path(), "services", "api")
+			})
+
+			It("opens the enclosing repository", func() {
+				var err error
+				uut, err = NewGitRepo(aConfig(), subdir)
+				Expect(err).ToNot(HaveOccurred())
+
+				actualResult, err := uut.LatestTaggedRelease()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actualResult.String()).To(Equal("1.0.0"))
+
+				actualCommits, err := uut.CommitMessagesSince(actualResult)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(Equal([]string{"five", "four", "three", "two"}))
+			})
+
+			It("only returns the commits changing the subdirectory if scoped to it", func() {
+				var err error
+				uut, err = NewGitRepo(aConfig(withScopeToDir), subdir)
+				Expect(err).ToNot(HaveOccurred())
+
+				actualCommits, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(Equal([]string{"two"}))
+			})
+
+			It("applies the path filters relative to the repository root within the scope", func() {
+				var err error
+				uut, err = NewGitRepo(aConfig(withScopeToDir, withIncludeFilters("services")), subdir)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(uut.FiltersAccept("services/api/two")).To(BeTrue())
+				Expect(uut.FiltersAccept("services/web/three")).To(BeFalse())
+			})
+		})
+
+		When("the path is the root of the repository", func() {
+			It("does not restrict the commits if scoped to it", func() {
+				var err error
+				uut, err = NewGitRepo(aConfig(withScopeToDir), bed.Path())
+				Expect(err).ToNot(HaveOccurred())
+
+				actualCommits, err := uut.CommitMessagesSince(semver.MustParse("1.0.0"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(HaveLen(4))
+			})
+		})
+
+		When("the path is a linked worktree", func() {
+			var worktree string
+			BeforeEach(func() {
+				var err error
+				worktree, err = os.MkdirTemp(os.TempDir(), "worktree-")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(os.Remove(worktree)).To(Succeed())
+				RunCommand("git", "-C", bed.Path(), "worktree", "add", "--quiet", "--detach", worktree, "HEAD~1").ExpectSuccess()
+			})
+			AfterEach(func() {
+				Expect(os.RemoveAll(worktree)).To(Succeed())
+			})
+
+			It("reads the tags of the main repository and the HEAD of the worktree", func() {
+				var err error
+				uut, err = NewGitRepo(aConfig(withScopeToDir), filepath.Join(worktree, "services", "api"))
+				Expect(err).ToNot(HaveOccurred())
+
+				actualResult, err := uut.LatestTaggedRelease()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(actualResult.String()).To(Equal("1.0.0"))
+
+				actualCommits, err := uut.CommitMessagesSince(actualResult)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messagesFrom(actualCommits...)).To(Equal([]string{"two"}))
+			})
+		})
+	})
+
+	Describe("FilesAt", func() {
+		var acceptAll = func(string) bool { return true }
+		BeforeEach(func() {
+			bed.
+				AddCommitWithContent("one", map[string]string{"a": "a-1", "dir/b": "b-1"}).
+				AddLightweightTag("1.0.0").
+				AddCommitWithContent("two", map[string]string{"a": "a-2"})
+		})
+
+		It("returns the files of the tagged commit", func() {
+			files, err := uut.FilesAt(semver.MustParse("1.0.0"), acceptAll)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(Equal(map[string][]byte{"a": []byte("a-1"), "dir/b": []byte("b-1")}))
+		})
+
+		It("returns the files of HEAD if the version is nil", func() {
+			files, err := uut.FilesAt(nil, acceptAll)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(Equal(map[string][]byte{"a": []byte("a-2"), "dir/b": []byte("b-1")}))
+		})
+
+		It("returns only the accepted files", func() {
+			files, err := uut.FilesAt(nil, func(name string) bool { return name == "dir/b" })
+			Expect(err).ToNot(HaveOccurred())
+			Expect(files).To(HaveKey("dir/b"))
+			Expect(files).To(HaveLen(1))
+		})
+
+		It("returns an error if there is no tag for the version", func() {
+			_, err := uut.FilesAt(semver.MustParse("2.0.0"), acceptAll)
+			Expect(err).To(HaveOccurred())
+		})
 	})
 
 	When("the directory is not a git repo", func() {
@@ -451,6 +1113,42 @@ func withExcludeFilters(filter ...string) func(p *Options) {
 	}
 }
 
+func withMaxCommits(max int) func(p *Options) {
+	return func(p *Options) {
+		p.MaxCommits = max
+	}
+}
+
+func withCommitGraph(p *Options) {
+	p.CommitGraph = true
+}
+
+func withScopeToDir(p *Options) {
+	p.ScopeToDir = true
+}
+
+func withAllowShallow(p *Options) {
+	p.AllowShallow = true
+}
+
+func withPathMax(pattern, level string) func(p *Options) {
+	return func(p *Options) {
+		if p.PathMax == nil {
+			p.PathMax = make(map[string]string)
+		}
+		p.PathMax[pattern] = level
+	}
+}
+
+func withPathMin(pattern, level string) func(p *Options) {
+	return func(p *Options) {
+		if p.PathMin == nil {
+			p.PathMin = make(map[string]string)
+		}
+		p.PathMin[pattern] = level
+	}
+}
+
 func consistOfCommits(index ...int) types.GomegaMatcher {
 	var result []interface{}
 	for _, i := range index {
@@ -0,0 +1,145 @@
+package gitrepo
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// History walks the first parent chain of HEAD once, the oldest commit first
+type History struct {
+	Gitrepo
+
+	since *object.Commit
+	seen  map[plumbing.Hash]bool
+	// shallow are the commits of a shallow clone whose parents are missing
+	shallow map[plumbing.Hash]bool
+}
+
+// NewHistory starts the history after the given commit, or at the root commit if since is nil.
+// Like the history walk of the next version, it fails for a shallow clone unless allowed,
+// and when the history after since reaches the shallow boundary.
+func (g Gitrepo) NewHistory(since *object.Commit) (*History, error) {
+	shallow, err := g.allowedShallowBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	h := &History{Gitrepo: g, since: since, seen: make(map[plumbing.Hash]bool), shallow: shallow}
+	if since != nil {
+		if _, err := h.walk(since, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return h, nil
+}
+
+// CommitOf resolves a revision like "v1.2.3" or "HEAD~3"
+func (g Gitrepo) CommitOf(revision string) (*object.Commit, error) {
+	hash, err := g.repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %v: %w", revision, err)
+	}
+
+	commit, err := g.repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get commit %v: %w", hash, err)
+	}
+
+	return commit, nil
+}
+
+// FirstParents returns the commits on the first parent chain of HEAD after the start of the history, the oldest first
+func (h *History) FirstParents() ([]*object.Commit, error) {
+	commit, err := h.headCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*object.Commit
+	for commit != nil && !h.seen[commit.Hash] {
+		result = append([]*object.Commit{commit}, result...)
+
+		if commit.NumParents() == 0 {
+			commit = nil
+		} else if h.shallow[commit.Hash] {
+			return nil, incompleteHistoryError(h.since)
+		} else if commit, err = commit.Parent(0); err != nil {
+			return nil, fmt.Errorf("cannot get parent of %v: %w", result[0].Hash, err)
+		}
+	}
+
+	if h.since != nil && (commit == nil || commit.Hash != h.since.Hash) {
+		return nil, fmt.Errorf("commit %v is not on the first parent chain of HEAD", h.since.Hash)
+	}
+
+	return result, nil
+}
+
+// CommitsUntil returns the commits reachable from the given commit which were not returned by a previous call,
+// and which change files accepted by the path filters
+func (h *History) CommitsUntil(commit *object.Commit) ([]*object.Commit, error) {
+	commits, err := h.walk(commit, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if !h.hasPathFilters() {
+		return commits, nil
+	}
+
+	var result []*object.Commit
+	for _, c := range commits {
+		files, err := changedFiles(c)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list changed files of commit %v: %w", c.Hash, err)
+		}
+
+		for _, file := range files {
+			if h.FiltersAccept(file) {
+				result = append(result, c)
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// walk marks all commits reachable from the given commit as seen and returns the ones not seen before.
+// Reaching the shallow boundary fails because the history is incomplete, unless the walk is allowed to end there.
+func (h *History) walk(commit *object.Commit, toBoundary bool) ([]*object.Commit, error) {
+	var result []*object.Commit
+	queue := []*object.Commit{commit}
+	for len(queue) > 0 {
+		if err := h.canceled(); err != nil {
+			return nil, err
+		}
+
+		c := queue[0]
+		queue = queue[1:]
+		if h.seen[c.Hash] {
+			continue
+		}
+
+		h.seen[c.Hash] = true
+		result = append(result, c)
+
+		if h.shallow[c.Hash] {
+			if !toBoundary {
+				return nil, incompleteHistoryError(h.since)
+			}
+			continue
+		}
+
+		if err := c.Parents().ForEach(func(parent *object.Commit) error {
+			queue = append(queue, parent)
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("cannot get parents of %v: %w", c.Hash, err)
+		}
+	}
+
+	return result, nil
+}
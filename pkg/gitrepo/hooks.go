@@ -0,0 +1,102 @@
+package gitrepo
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HooksDir returns the directory git runs the hooks from: core.hooksPath if set, relative to the root
+// of the worktree, or the hooks directory of the git directory shared by all worktrees
+func (g Gitrepo) HooksDir() (string, error) {
+	hooksPath, err := g.hooksPathOption()
+	if err != nil {
+		return "", err
+	}
+
+	if hooksPath != "" {
+		return g.resolveHooksPath(hooksPath)
+	}
+
+	fs, ok := g.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", fmt.Errorf("the repository has no git directory")
+	}
+
+	common, err := commonDir(fs.Filesystem().Root())
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(common, "hooks"), nil
+}
+
+// hooksPathOption reads core.hooksPath from the repository, global, and system config, in that order
+func (g Gitrepo) hooksPathOption() (string, error) {
+	local, err := g.repo.Config()
+	if err != nil {
+		return "", fmt.Errorf("cannot read git config: %w", err)
+	}
+
+	if value := local.Raw.Section("core").Option("hooksPath"); value != "" {
+		return value, nil
+	}
+
+	for _, scope := range []gitconfig.Scope{gitconfig.GlobalScope, gitconfig.SystemScope} {
+		cfg, err := gitconfig.LoadConfig(scope)
+		if err != nil {
+			return "", fmt.Errorf("cannot read git config: %w", err)
+		}
+
+		if value := cfg.Raw.Section("core").Option("hooksPath"); value != "" {
+			return value, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (g Gitrepo) resolveHooksPath(hooksPath string) (string, error) {
+	if strings.HasPrefix(hooksPath, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve %v: %w", hooksPath, err)
+		}
+
+		return filepath.Join(home, hooksPath[2:]), nil
+	}
+
+	if filepath.IsAbs(hooksPath) {
+		return hooksPath, nil
+	}
+
+	worktree, err := g.repo.Worktree()
+	if err == git.ErrIsBareRepository {
+		return "", fmt.Errorf("cannot resolve the relative core.hooksPath %v in a bare repository", hooksPath)
+	} else if err != nil {
+		return "", fmt.Errorf("cannot get worktree: %w", err)
+	}
+
+	return filepath.Join(worktree.Filesystem.Root(), hooksPath), nil
+}
+
+// commonDir returns the git directory shared by the worktrees, named in the commondir file of a linked worktree
+func commonDir(gitDir string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if os.IsNotExist(err) {
+		return gitDir, nil
+	} else if err != nil {
+		return "", fmt.Errorf("cannot read commondir: %w", err)
+	}
+
+	dir := strings.TrimSpace(string(content))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+
+	return filepath.Clean(dir), nil
+}
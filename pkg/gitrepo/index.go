@@ -0,0 +1,62 @@
+package gitrepo
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// tagIndex holds the version tags of a repository, they are read once and shared by all copies of a Gitrepo.
+// The commits of the tags are resolved on demand, most runs only need the commit of the latest release.
+type tagIndex struct {
+	versions collection
+	releases collection
+}
+
+// versionTags returns the releases and prereleases, or only the releases if strict, the latest one last
+func (g Gitrepo) versionTags(strict bool) (collection, error) {
+	if g.index.versions == nil {
+		versions, err := g.readVersionTags()
+		if err != nil {
+			return nil, err
+		}
+
+		g.index.versions, g.index.releases = versions, versions.releases()
+		g.log.Info("version tags", "versions", len(g.index.versions), "releases", len(g.index.releases))
+	}
+
+	if strict {
+		return g.index.releases, nil
+	}
+
+	return g.index.versions, nil
+}
+
+func (g Gitrepo) readVersionTags() (collection, error) {
+	iter, err := g.repo.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list git tags: %w", err)
+	}
+
+	c := g.newCollector()
+	collect := func(ref *plumbing.Reference) error {
+		if err := g.canceled(); err != nil {
+			return err
+		}
+
+		return c.collect(ref)
+	}
+	if err := iter.ForEach(collect); err != nil {
+		return nil, err
+	}
+
+	result := collection{}
+	result = append(result, c.Result...)
+	result.sortBy(g.conf.SchemeValue())
+
+	return result, nil
+}
+
+// invalidate forgets the tags after a tag was created
+func (g Gitrepo) invalidate() {
+	g.index.versions, g.index.releases = nil, nil
+}
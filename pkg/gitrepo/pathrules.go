@@ -0,0 +1,151 @@
+package gitrepo
+
+import (
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"path/filepath"
+)
+
+func (g Gitrepo) LevelLimits(commit *object.Commit) (LevelLimits, error) {
+	limits := NoLevelLimits()
+	if len(g.conf.PathMaxValue()) == 0 && len(g.conf.PathMinValue()) == 0 {
+		return limits, nil
+	}
+
+	files, err := changedFiles(commit)
+	if err != nil {
+		return limits, fmt.Errorf("cannot list changed files of commit %v: %w", commit.Hash, err)
+	}
+
+	if len(files) == 0 {
+		return limits, nil
+	}
+
+	limits.Cap = BumpLevelNone
+	for _, file := range files {
+		limits.Cap = limits.Cap.Max(fileCap(g.conf.PathMaxValue(), file))
+		limits.Floor = limits.Floor.Max(fileFloor(g.conf.PathMinValue(), file))
+	}
+
+	return limits, nil
+}
+
+// fileCap returns the most permissive cap of all patterns matching the file,
+// files matching no pattern are not capped at all.
+func fileCap(rules map[string]BumpLevel, file string) BumpLevel {
+	matched := false
+	result := BumpLevelNone
+	for pattern, lvl := range rules {
+		if !pathMatches(pattern, file) {
+			continue
+		}
+
+		matched = true
+		result = result.Max(lvl)
+	}
+
+	if !matched {
+		return BumpLevelMajor
+	}
+
+	return result
+}
+
+func fileFloor(rules map[string]BumpLevel, file string) BumpLevel {
+	result := BumpLevelNone
+	for pattern, lvl := range rules {
+		if pathMatches(pattern, file) {
+			result = result.Max(lvl)
+		}
+	}
+
+	return result
+}
+
+// pathMatches is true if the filepath.Match pattern matches the file or any of its parent directories,
+// so "docs" and "docs/**" cover the whole directory. A "**" matches like "*" within a single path segment,
+// "docs/**/*.md" does not match "docs/a/b/c.md".
+func pathMatches(pattern, file string) bool {
+	for _, name := range allTheWayDown(file) {
+		if match, _ := filepath.Match(pattern, name); match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// changedFiles returns the files changed by the commit, a merge changes the files which differ from each parent
+func changedFiles(commit *object.Commit) ([]string, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	if commit.NumParents() == 0 {
+		return diffNames(nil, tree)
+	}
+
+	var result []string
+	for i := 0; i < commit.NumParents(); i++ {
+		parent, err := commit.Parent(i)
+		if err != nil {
+			return nil, err
+		}
+
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+
+		names, err := diffNames(parentTree, tree)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			result = names
+		} else {
+			result = intersection(result, names)
+		}
+	}
+
+	return result, nil
+}
+
+func diffNames(from, to *object.Tree) ([]string, error) {
+	changes, err := object.DiffTree(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+
+		result = append(result, name)
+	}
+
+	return result, nil
+}
+
+// intersection returns the names of a which are also in b
+func intersection(a, b []string) []string {
+	inB := make(map[string]bool)
+	for _, name := range b {
+		inB[name] = true
+	}
+
+	var result []string
+	for _, name := range a {
+		if inB[name] {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,107 @@
+package gitrepo
+
+import (
+	"container/heap"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	commitgraphobject "github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+)
+
+// commitQueue orders the commits of a history walk by their commit time, the latest first. A commit reachable
+// from an excluded commit is excluded as well, like the commits reachable from the start of a revision range.
+// On equal commit times the excluded commits come first, so that their ancestors with the same timestamp are
+// excluded before they could be visited.
+type commitQueue struct {
+	entries  []queueEntry
+	excluded map[plumbing.Hash]bool
+	queued   map[plumbing.Hash]bool
+	done     map[plumbing.Hash]bool
+	// interesting is the number of queued entries which are not excluded, the walk ends when it is 0
+	interesting int
+}
+
+type queueEntry struct {
+	node     commitgraphobject.CommitNode
+	excluded bool
+}
+
+func newCommitQueue() *commitQueue {
+	return &commitQueue{
+		excluded: make(map[plumbing.Hash]bool),
+		queued:   make(map[plumbing.Hash]bool),
+		done:     make(map[plumbing.Hash]bool),
+	}
+}
+
+// add queues a commit unless it is queued already, an excluded commit is queued again if it was queued before
+func (q *commitQueue) add(node commitgraphobject.CommitNode, excluded bool) {
+	id := node.ID()
+	if q.done[id] || q.excluded[id] || (!excluded && q.queued[id]) {
+		return
+	}
+
+	if excluded {
+		q.excluded[id] = true
+	} else {
+		q.queued[id] = true
+		q.interesting++
+	}
+	heap.Push(q, queueEntry{node: node, excluded: excluded})
+}
+
+// addParents queues the parents of the commit, they are excluded if the commit is
+func (q *commitQueue) addParents(node commitgraphobject.CommitNode, excluded bool) error {
+	for i := 0; i < node.NumParents(); i++ {
+		parent, err := node.ParentNode(i)
+		if err != nil {
+			return fmt.Errorf("cannot get parent of %v: %w", node.ID(), err)
+		}
+		q.add(parent, excluded)
+	}
+
+	return nil
+}
+
+// next returns the latest commit and whether it is excluded, ok is false for a commit returned before
+func (q *commitQueue) next() (node commitgraphobject.CommitNode, excluded, ok bool) {
+	entry := heap.Pop(q).(queueEntry)
+	if !entry.excluded {
+		q.interesting--
+	}
+
+	id := entry.node.ID()
+	if q.done[id] {
+		return nil, false, false
+	}
+	q.done[id] = true
+
+	return entry.node, q.excluded[id], true
+}
+
+func (q *commitQueue) Len() int {
+	return len(q.entries)
+}
+
+func (q *commitQueue) Less(i, j int) bool {
+	a, b := q.entries[i], q.entries[j]
+	if ta, tb := a.node.CommitTime(), b.node.CommitTime(); !ta.Equal(tb) {
+		return ta.After(tb)
+	}
+
+	return a.excluded && !b.excluded
+}
+
+func (q *commitQueue) Swap(i, j int) {
+	q.entries[i], q.entries[j] = q.entries[j], q.entries[i]
+}
+
+func (q *commitQueue) Push(x interface{}) {
+	q.entries = append(q.entries, x.(queueEntry))
+}
+
+func (q *commitQueue) Pop() interface{} {
+	last := q.entries[len(q.entries)-1]
+	q.entries = q.entries[:len(q.entries)-1]
+
+	return last
+}
@@ -0,0 +1,43 @@
+package gitrepo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	. "github.com/timotto/semver-bumper/pkg/config"
+)
+
+const pushRemoteName = "semver-bumper"
+
+// Clone clones the branch of the repository at the url with its tags into a bare repository in dir,
+// the default branch if branch is empty, auth may be nil
+func Clone(conf *Options, url, branch, dir string, auth transport.AuthMethod) (*Gitrepo, error) {
+	opts := &git.CloneOptions{URL: url, Auth: auth, Tags: git.AllTags}
+	if branch != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(branch)
+		opts.SingleBranch = true
+	}
+
+	repo, err := git.PlainClone(dir, true, opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot clone %v: %w", url, err)
+	}
+
+	return &Gitrepo{conf: conf, repo: repo, index: &tagIndex{}, patterns: newGitignorePatterns(conf)}, nil
+}
+
+// PushTag pushes the tag with the given name to the repository at the url, auth may be nil
+func (g Gitrepo) PushTag(name, url string, auth transport.AuthMethod) error {
+	remote := git.NewRemote(g.repo.Storer, &gitconfig.RemoteConfig{Name: pushRemoteName, URLs: []string{url}})
+	refSpec := gitconfig.RefSpec(fmt.Sprintf("refs/tags/%v:refs/tags/%v", name, name))
+
+	err := remote.Push(&git.PushOptions{RemoteName: pushRemoteName, Auth: auth, RefSpecs: []gitconfig.RefSpec{refSpec}})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("cannot push tag %v to %v: %w", name, url, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package gitrepo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ErrShallowClone is the cause of the errors about missing history in a shallow clone
+var ErrShallowClone = errors.New("the repository is a shallow clone")
+
+// shallowBoundary returns the commits listed in the shallow file of a shallow clone, their parents are missing
+// from the object storage. It is empty if the repository has the full history.
+func (g Gitrepo) shallowBoundary() (map[plumbing.Hash]bool, error) {
+	hashes, err := g.repo.Storer.Shallow()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the shallow commits: %w", err)
+	}
+
+	shallow := make(map[plumbing.Hash]bool)
+	for _, hash := range hashes {
+		shallow[hash] = true
+	}
+
+	return shallow, nil
+}
+
+// allowedShallowBoundary is the shallow boundary of a repository with the full history or of an allowed shallow clone,
+// otherwise it fails with ErrShallowClone
+func (g Gitrepo) allowedShallowBoundary() (map[plumbing.Hash]bool, error) {
+	shallow, err := g.shallowBoundary()
+	if err != nil || len(shallow) == 0 {
+		return shallow, err
+	}
+
+	if !g.conf.AllowShallowClone() {
+		return nil, shallowCloneError()
+	}
+	g.log.Info("shallow clone", "boundary", len(shallow))
+
+	return shallow, nil
+}
+
+func shallowCloneError() error {
+	return fmt.Errorf("%w, the commits since the latest release may be missing: "+
+		"fetch the full history with \"git fetch --unshallow --tags\", "+
+		"or use --allow-shallow if the history since the latest release tag is present", ErrShallowClone)
+}
+
+// incompleteHistoryError is the error for a walk reaching the shallow boundary before the commit of the latest release
+func incompleteHistoryError(until *object.Commit) error {
+	if until == nil {
+		return fmt.Errorf("%w without a release tag, the full history is required for the first release: "+
+			"fetch it with \"git fetch --unshallow --tags\"", ErrShallowClone)
+	}
+
+	return fmt.Errorf("%w and the history since the latest release %v is incomplete: "+
+		"fetch more with \"git fetch --deepen=100\" or \"git fetch --unshallow\"", ErrShallowClone, until.Hash.String()[:7])
+}
@@ -0,0 +1,304 @@
+package gitrepo
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/commitgraph"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	commitgraphobject "github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/timotto/semver-bumper/pkg/logger"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+const commitGraphFile = "objects/info/commit-graph"
+
+// progressInterval is the time between two progress reports of a long history walk
+var progressInterval = 5 * time.Second
+
+// walkLog visits the commits reachable from the given commit but not from until, the latest commit time first,
+// like "git log until..from". With path filters a commit is visited when it changes an accepted path compared to
+// each of its parents, without them every commit is visited. The walk ends when visit returns storer.ErrStop,
+// or when the context is done. In a shallow clone the walk fails unless allowed, and when it reaches the
+// shallow boundary outside the history of until.
+func (g Gitrepo) walkLog(from plumbing.Hash, until *object.Commit, visit func(*object.Commit) error) error {
+	shallow, err := g.allowedShallowBoundary()
+	if err != nil {
+		return err
+	}
+
+	index, closer, err := g.commitNodeIndex()
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	start, err := index.Get(from)
+	if err != nil {
+		return fmt.Errorf("cannot get commit %v: %w", from, err)
+	}
+
+	queue := newCommitQueue()
+	queue.add(start, false)
+	if until != nil {
+		node, err := index.Get(until.Hash)
+		if err != nil {
+			return fmt.Errorf("cannot get commit %v: %w", until.Hash, err)
+		}
+		queue.add(node, true)
+	}
+
+	filtered := g.hasPathFilters()
+	limit, limited := g.conf.ShouldLimitCommits()
+	report := newProgress(g.log)
+	defer report.done()
+	for queue.interesting > 0 {
+		if err := g.canceled(); err != nil {
+			return err
+		}
+
+		node, excluded, ok := queue.next()
+		if !ok {
+			continue
+		}
+
+		// the parents of the shallow commits are missing
+		if shallow[node.ID()] {
+			if excluded {
+				continue
+			}
+			return incompleteHistoryError(until)
+		}
+
+		if err := queue.addParents(node, excluded); err != nil {
+			return err
+		}
+		if excluded {
+			continue
+		}
+
+		report.walked++
+		if limited && report.walked > limit {
+			return fmt.Errorf("the history walk visited more than %d commits, see --max-commits", limit)
+		}
+		report.update()
+
+		if filtered {
+			ok, err := g.changesAcceptedPath(node)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				if g.log.Enabled(logger.LevelDebug) {
+					g.log.Debug("commit skipped", "commit", node.ID(), "reason", "no accepted path changed")
+				}
+				continue
+			}
+		}
+
+		commit, err := node.Commit()
+		if err != nil {
+			return fmt.Errorf("cannot get commit %v: %w", node.ID(), err)
+		}
+
+		if err := visit(commit); err != nil {
+			if errors.Is(err, storer.ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// commitNodeIndex reads the commits from the commit-graph file if enabled and present,
+// from the object storage otherwise
+func (g Gitrepo) commitNodeIndex() (commitgraphobject.CommitNodeIndex, io.Closer, error) {
+	objects := commitgraphobject.NewObjectCommitNodeIndex(g.repo.Storer)
+	if !g.conf.UseCommitGraph() {
+		return objects, io.NopCloser(nil), nil
+	}
+
+	fs, ok := g.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return objects, io.NopCloser(nil), nil
+	}
+
+	file, err := fs.Filesystem().Open(commitGraphFile)
+	if os.IsNotExist(err) {
+		return objects, io.NopCloser(nil), nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("cannot open commit-graph: %w", err)
+	}
+
+	graph, err := commitgraph.OpenFileIndex(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, nil, fmt.Errorf("cannot read commit-graph: %w", err)
+	}
+
+	return commitgraphobject.NewGraphCommitNodeIndex(graph, g.repo.Storer), file, nil
+}
+
+// changesAcceptedPath is true if the commit changes an accepted path compared to each parent, a merge taking
+// all accepted paths of one parent is skipped just like git log skips it
+func (g Gitrepo) changesAcceptedPath(node commitgraphobject.CommitNode) (bool, error) {
+	tree, err := node.Tree()
+	if err != nil {
+		return false, fmt.Errorf("cannot get tree of %v: %w", node.ID(), err)
+	}
+
+	if node.NumParents() == 0 {
+		return g.treeChangesAccepted(nil, tree, "")
+	}
+
+	for i := 0; i < node.NumParents(); i++ {
+		parent, err := node.ParentNode(i)
+		if err != nil {
+			return false, fmt.Errorf("cannot get parent of %v: %w", node.ID(), err)
+		}
+
+		parentTree, err := parent.Tree()
+		if err != nil {
+			return false, fmt.Errorf("cannot get tree of %v: %w", parent.ID(), err)
+		}
+
+		if ok, err := g.treeChangesAccepted(parentTree, tree, ""); err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// treeChangesAccepted compares the trees entry by entry and stops at the first changed file accepted by the path
+// filters. Subtrees with the same hash are unchanged and skipped, so are excluded directories.
+func (g Gitrepo) treeChangesAccepted(from, to *object.Tree, dir string) (bool, error) {
+	if from != nil && to != nil && from.Hash == to.Hash {
+		return false, nil
+	}
+
+	fromEntries := make(map[string]object.TreeEntry)
+	if from != nil {
+		for _, entry := range from.Entries {
+			fromEntries[entry.Name] = entry
+		}
+	}
+
+	var toEntries []object.TreeEntry
+	if to != nil {
+		toEntries = to.Entries
+	}
+
+	for _, entry := range toEntries {
+		previous, ok := fromEntries[entry.Name]
+		delete(fromEntries, entry.Name)
+
+		if ok && previous.Hash == entry.Hash && previous.Mode == entry.Mode {
+			continue
+		}
+
+		var before *object.TreeEntry
+		if ok {
+			before = &previous
+		}
+
+		if changed, err := g.entryChangesAccepted(before, &entry, path.Join(dir, entry.Name)); err != nil || changed {
+			return changed, err
+		}
+	}
+
+	for name, entry := range fromEntries {
+		entry := entry
+		if changed, err := g.entryChangesAccepted(&entry, nil, path.Join(dir, name)); err != nil || changed {
+			return changed, err
+		}
+	}
+
+	return false, nil
+}
+
+// entryChangesAccepted compares two versions of a changed entry, either one is nil if the entry was added or removed
+func (g Gitrepo) entryChangesAccepted(from, to *object.TreeEntry, name string) (bool, error) {
+	if !isDir(from) && !isDir(to) {
+		return g.FiltersAccept(name), nil
+	}
+
+	// a file replaced by a directory or the other way round
+	if (from != nil && !isDir(from)) || (to != nil && !isDir(to)) {
+		if g.FiltersAccept(name) {
+			return true, nil
+		}
+	}
+
+	if g.dirRejected(name) {
+		return false, nil
+	}
+
+	fromTree, err := g.subtree(from)
+	if err != nil {
+		return false, err
+	}
+
+	toTree, err := g.subtree(to)
+	if err != nil {
+		return false, err
+	}
+
+	return g.treeChangesAccepted(fromTree, toTree, name)
+}
+
+func (g Gitrepo) subtree(entry *object.TreeEntry) (*object.Tree, error) {
+	if !isDir(entry) {
+		return nil, nil
+	}
+
+	tree, err := g.repo.TreeObject(entry.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get tree %v: %w", entry.Hash, err)
+	}
+
+	return tree, nil
+}
+
+func isDir(entry *object.TreeEntry) bool {
+	return entry != nil && entry.Mode == filemode.Dir
+}
+
+// progress logs the number of walked commits, only once the walk takes longer than the interval
+type progress struct {
+	log      *logger.Logger
+	walked   int
+	started  time.Time
+	reported time.Time
+}
+
+func newProgress(log *logger.Logger) *progress {
+	now := time.Now()
+	return &progress{log: log, started: now, reported: now}
+}
+
+func (p *progress) update() {
+	if time.Since(p.reported) < progressInterval {
+		return
+	}
+
+	p.reported = time.Now()
+	p.log.Warn("long history walk", "walked", p.walked, "elapsed", p.reported.Sub(p.started).Round(time.Second))
+}
+
+func (p *progress) done() {
+	if p.reported == p.started {
+		p.log.Debug("history walk done", "walked", p.walked)
+		return
+	}
+
+	p.log.Warn("long history walk done", "walked", p.walked, "elapsed", time.Since(p.started).Round(time.Second))
+}
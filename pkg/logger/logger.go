@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Level is the verbosity of a message, a logger writes the messages up to its own level
+type Level int
+
+const (
+	LevelWarn Level = iota
+	LevelInfo
+	LevelDebug
+)
+
+var levelNames = map[Level]string{
+	LevelWarn:  "warn",
+	LevelInfo:  "info",
+	LevelDebug: "debug",
+}
+
+// Logger writes one line per message with key/value pairs, either logfmt like
+// `level=info msg="commit range" from=1a2b3c4 since=v1.2.3` or as a JSON object.
+// A nil Logger discards all messages.
+type Logger struct {
+	w     io.Writer
+	level Level
+	json  bool
+	mu    sync.Mutex
+}
+
+// New returns a logger writing the messages up to the given level in the given format, text if empty
+func New(w io.Writer, level Level, format string) *Logger {
+	return &Logger{w: w, level: level, json: format == FormatJSON}
+}
+
+// Enabled is true if messages of the level are written, to skip expensive key/value pairs
+func (l *Logger) Enabled(level Level) bool {
+	return l != nil && level <= l.level
+}
+
+// Warn writes a message at any verbosity
+func (l *Logger) Warn(msg string, keyvals ...interface{}) {
+	l.log(LevelWarn, msg, keyvals)
+}
+
+// Info writes a message with -v
+func (l *Logger) Info(msg string, keyvals ...interface{}) {
+	l.log(LevelInfo, msg, keyvals)
+}
+
+// Debug writes a message with -vv
+func (l *Logger) Debug(msg string, keyvals ...interface{}) {
+	l.log(LevelDebug, msg, keyvals)
+}
+
+func (l *Logger) log(level Level, msg string, keyvals []interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	keyvals = append([]interface{}{"level", levelNames[level], "msg", msg}, keyvals...)
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, "(missing)")
+	}
+
+	var line []byte
+	if l.json {
+		line = jsonLine(keyvals)
+	} else {
+		line = textLine(keyvals)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = l.w.Write(line)
+}
+
+func textLine(keyvals []interface{}) []byte {
+	buf := &bytes.Buffer{}
+	for i := 0; i < len(keyvals); i += 2 {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+
+		buf.WriteString(fmt.Sprint(keyvals[i]))
+		buf.WriteByte('=')
+		buf.WriteString(quote(fmt.Sprint(value(keyvals[i+1]))))
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes()
+}
+
+func jsonLine(keyvals []interface{}) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i := 0; i < len(keyvals); i += 2 {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(fmt.Sprint(keyvals[i]))
+		val, err := json.Marshal(value(keyvals[i+1]))
+		if err != nil {
+			val, _ = json.Marshal(fmt.Sprint(keyvals[i+1]))
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteString("}\n")
+
+	return buf.Bytes()
+}
+
+// value is the text of errors and stringers, eg versions and hashes, nil pointers stay nil
+func value(v interface{}) interface{} {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil
+	}
+
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return v
+	}
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
@@ -0,0 +1,48 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/Masterminds/semver/v3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/pkg/logger"
+)
+
+var _ = Describe("Logger", func() {
+	var buf *bytes.Buffer
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+	})
+
+	It("writes the messages up to its level", func() {
+		uut := New(buf, LevelInfo, FormatText)
+		uut.Warn("a warning")
+		uut.Info("an info")
+		uut.Debug("a debug message")
+
+		Expect(buf.String()).To(Equal("level=warn msg=\"a warning\"\nlevel=info msg=\"an info\"\n"))
+		Expect(uut.Enabled(LevelInfo)).To(BeTrue())
+		Expect(uut.Enabled(LevelDebug)).To(BeFalse())
+	})
+
+	It("writes logfmt key/value pairs as text", func() {
+		New(buf, LevelDebug, "").Debug("tag found", "tag", "v1.2.3", "version", semver.MustParse("1.2.3"), "count", 2, "reason", "no prefix", "empty", "")
+
+		Expect(buf.String()).To(Equal("level=debug msg=\"tag found\" tag=v1.2.3 version=1.2.3 count=2 reason=\"no prefix\" empty=\"\"\n"))
+	})
+
+	It("writes JSON objects in the JSON format", func() {
+		var nilVersion *semver.Version
+		New(buf, LevelWarn, FormatJSON).Warn("failed", "err", errors.New("some error"), "count", 2, "version", nilVersion, "odd")
+
+		Expect(buf.String()).To(Equal(`{"level":"warn","msg":"failed","err":"some error","count":2,"version":null,"odd":"(missing)"}` + "\n"))
+	})
+
+	It("discards all messages if nil", func() {
+		var uut *Logger
+		uut.Warn("a warning")
+
+		Expect(uut.Enabled(LevelWarn)).To(BeFalse())
+	})
+})
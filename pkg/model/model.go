@@ -1,5 +1,7 @@
 package model
 
+import "fmt"
+
 type BumpLevel int
 
 const (
@@ -8,3 +10,63 @@ const (
 	BumpLevelMinor
 	BumpLevelMajor
 )
+
+var bumpLevelNames = []string{"none", "patch", "minor", "major"}
+
+func ParseBumpLevel(val string) (BumpLevel, error) {
+	for i, name := range bumpLevelNames {
+		if name == val {
+			return BumpLevel(i), nil
+		}
+	}
+
+	return BumpLevelNone, fmt.Errorf("invalid bump level: %v", val)
+}
+
+func (l BumpLevel) String() string {
+	if l < BumpLevelNone || l > BumpLevelMajor {
+		return fmt.Sprintf("BumpLevel(%d)", int(l))
+	}
+
+	return bumpLevelNames[l]
+}
+
+func (l BumpLevel) Max(other BumpLevel) BumpLevel {
+	if other > l {
+		return other
+	}
+
+	return l
+}
+
+// LevelLimits caps and raises the bump level of a single commit.
+type LevelLimits struct {
+	Floor BumpLevel
+	Cap   BumpLevel
+}
+
+func NoLevelLimits() LevelLimits {
+	return LevelLimits{
+		Floor: BumpLevelNone,
+		Cap:   BumpLevelMajor,
+	}
+}
+
+func (l LevelLimits) Apply(lvl BumpLevel) BumpLevel {
+	if lvl > l.Cap {
+		lvl = l.Cap
+	}
+
+	return lvl.Max(l.Floor)
+}
+
+// Change is a difference an analyzer detected between the latest release and HEAD.
+type Change struct {
+	Source      string
+	Level       BumpLevel
+	Description string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s", c.Source, c.Description)
+}
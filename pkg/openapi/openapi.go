@@ -0,0 +1,386 @@
+package openapi
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"gopkg.in/yaml.v3"
+	"sort"
+	"strings"
+)
+
+const Source = "openapi"
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+type (
+	FileReader interface {
+		FilesAt(v *semver.Version, accept func(name string) bool) (map[string][]byte, error)
+	}
+
+	analyzer struct {
+		repo  FileReader
+		specs []string
+	}
+
+	document = map[string]interface{}
+)
+
+func NewAnalyzer(repo FileReader, specs []string) *analyzer {
+	return &analyzer{
+		repo:  repo,
+		specs: specs,
+	}
+}
+
+func (a analyzer) Analyze(since *semver.Version) ([]Change, error) {
+	if since == nil {
+		return nil, nil
+	}
+
+	before, err := a.repo.FilesAt(since, a.isSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := a.repo.FilesAt(nil, a.isSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Change
+	for _, spec := range a.specs {
+		changes, err := Diff(spec, before[spec], after[spec])
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, changes...)
+	}
+
+	return result, nil
+}
+
+func (a analyzer) isSpec(name string) bool {
+	for _, spec := range a.specs {
+		if spec == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Diff compares two versions of an OpenAPI document or JSON schema.
+// A nil version means the file does not exist.
+func Diff(name string, before, after []byte) ([]Change, error) {
+	switch {
+	case before == nil && after == nil:
+		return nil, nil
+	case after == nil:
+		return []Change{change(BumpLevelMajor, "%v: removed", name)}, nil
+	case before == nil:
+		return []Change{change(BumpLevelMinor, "%v: added", name)}, nil
+	}
+
+	beforeDoc, err := parse(name, before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterDoc, err := parse(name, after)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &differ{name: name}
+	if isOpenApi(beforeDoc) || isOpenApi(afterDoc) {
+		d.paths(mapAt(beforeDoc, "paths"), mapAt(afterDoc, "paths"))
+		d.schemas("components.schemas", mapAt(beforeDoc, "components", "schemas"), mapAt(afterDoc, "components", "schemas"))
+		d.schemas("definitions", mapAt(beforeDoc, "definitions"), mapAt(afterDoc, "definitions"))
+	} else {
+		d.schema("schema", beforeDoc, afterDoc, true)
+		d.schemas("definitions", mapAt(beforeDoc, "definitions"), mapAt(afterDoc, "definitions"))
+		d.schemas("$defs", mapAt(beforeDoc, "$defs"), mapAt(afterDoc, "$defs"))
+	}
+
+	return d.changes, nil
+}
+
+func parse(name string, data []byte) (document, error) {
+	result := make(document)
+	if err := yaml.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("cannot parse %v: %w", name, err)
+	}
+
+	return normalize(result).(document), nil
+}
+
+// normalize turns the keys of all maps into strings, yaml decodes a map with
+// unquoted status codes like `200:` into a map with int keys
+func normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case document:
+		result := make(document, len(val))
+		for key, item := range val {
+			result[key] = normalize(item)
+		}
+		return result
+
+	case map[interface{}]interface{}:
+		result := make(document, len(val))
+		for key, item := range val {
+			result[fmt.Sprint(key)] = normalize(item)
+		}
+		return result
+
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			result[i] = normalize(item)
+		}
+		return result
+
+	default:
+		return v
+	}
+}
+
+func isOpenApi(doc document) bool {
+	_, openApi := doc["openapi"]
+	_, swagger := doc["swagger"]
+
+	return openApi || swagger
+}
+
+type differ struct {
+	name    string
+	changes []Change
+}
+
+func (d *differ) add(lvl BumpLevel, format string, args ...interface{}) {
+	d.changes = append(d.changes, change(lvl, d.name+": "+format, args...))
+}
+
+func (d *differ) paths(before, after document) {
+	for _, path := range sortedKeys(before, after) {
+		beforeItem, afterItem := mapAt(before, path), mapAt(after, path)
+		for _, method := range methods {
+			beforeOp, beforeOk := beforeItem[method].(document)
+			afterOp, afterOk := afterItem[method].(document)
+			endpoint := fmt.Sprintf("%v %v", strings.ToUpper(method), path)
+			switch {
+			case beforeOk && !afterOk:
+				d.add(BumpLevelMajor, "removed endpoint %v", endpoint)
+			case !beforeOk && afterOk:
+				d.add(BumpLevelMinor, "added endpoint %v", endpoint)
+			case beforeOk && afterOk:
+				d.operation(endpoint, beforeOp, afterOp)
+			}
+		}
+	}
+}
+
+func (d *differ) operation(endpoint string, before, after document) {
+	d.parameters(endpoint, parameters(before), parameters(after))
+	d.schema(endpoint+" request body", bodySchema(mapAt(before, "requestBody")), bodySchema(mapAt(after, "requestBody")), true)
+
+	beforeResponses, afterResponses := mapAt(before, "responses"), mapAt(after, "responses")
+	for _, status := range sortedKeys(beforeResponses, afterResponses) {
+		beforeResponse, beforeOk := beforeResponses[status].(document)
+		afterResponse, afterOk := afterResponses[status].(document)
+		label := fmt.Sprintf("%v response %v", endpoint, status)
+		switch {
+		case beforeOk && !afterOk:
+			d.add(BumpLevelMajor, "removed %v", label)
+		case !beforeOk && afterOk:
+			d.add(BumpLevelMinor, "added %v", label)
+		default:
+			d.schema(label, bodySchema(beforeResponse), bodySchema(afterResponse), false)
+		}
+	}
+}
+
+func (d *differ) parameters(endpoint string, before, after map[string]document) {
+	for _, key := range sortedKeys(before, after) {
+		beforeParam, beforeOk := before[key]
+		afterParam, afterOk := after[key]
+		label := fmt.Sprintf("parameter %v of %v", key, endpoint)
+		switch {
+		case beforeOk && !afterOk:
+			d.add(BumpLevelMajor, "removed %v", label)
+		case !beforeOk && isRequired(afterParam):
+			d.add(BumpLevelMajor, "added required %v", label)
+		case !beforeOk:
+			d.add(BumpLevelMinor, "added optional %v", label)
+		case !isRequired(beforeParam) && isRequired(afterParam):
+			d.add(BumpLevelMajor, "made %v required", label)
+		default:
+			d.schema(label, mapAt(beforeParam, "schema"), mapAt(afterParam, "schema"), true)
+		}
+	}
+}
+
+func (d *differ) schemas(label string, before, after document) {
+	for _, name := range sortedKeys(before, after) {
+		beforeSchema, beforeOk := before[name].(document)
+		afterSchema, afterOk := after[name].(document)
+		schemaLabel := fmt.Sprintf("%v.%v", label, name)
+		switch {
+		case beforeOk && !afterOk:
+			d.add(BumpLevelMajor, "removed schema %v", schemaLabel)
+		case !beforeOk && afterOk:
+			d.add(BumpLevelMinor, "added schema %v", schemaLabel)
+		case beforeOk && afterOk:
+			d.schema(schemaLabel, beforeSchema, afterSchema, true)
+		}
+	}
+}
+
+// schema compares two schemas, properties added to an input are only
+// compatible if they are optional
+func (d *differ) schema(label string, before, after document, input bool) {
+	if before == nil || after == nil {
+		return
+	}
+
+	if ref, otherRef := before["$ref"], after["$ref"]; ref != otherRef {
+		d.add(BumpLevelMajor, "changed %v from %v to %v", label, describe(before), describe(after))
+		return
+	}
+
+	if typ, otherTyp := before["type"], after["type"]; fmt.Sprint(typ) != fmt.Sprint(otherTyp) {
+		d.add(BumpLevelMajor, "changed type of %v from %v to %v", label, typ, otherTyp)
+		return
+	}
+
+	beforeRequired, afterRequired := required(before), required(after)
+	beforeProps, afterProps := mapAt(before, "properties"), mapAt(after, "properties")
+	for _, name := range sortedKeys(beforeProps, afterProps) {
+		beforeProp, beforeOk := beforeProps[name].(document)
+		afterProp, afterOk := afterProps[name].(document)
+		propLabel := fmt.Sprintf("property %v of %v", name, label)
+		switch {
+		case beforeOk && !afterOk:
+			d.add(BumpLevelMajor, "removed %v", propLabel)
+		case !beforeOk && afterOk && input && afterRequired[name]:
+			d.add(BumpLevelMajor, "added required %v", propLabel)
+		case !beforeOk && afterOk:
+			d.add(BumpLevelMinor, "added optional %v", propLabel)
+		case beforeOk && afterOk:
+			if input && !beforeRequired[name] && afterRequired[name] {
+				d.add(BumpLevelMajor, "made %v required", propLabel)
+			}
+			d.schema(propLabel, beforeProp, afterProp, input)
+		}
+	}
+
+	d.schema("items of "+label, mapAt(before, "items"), mapAt(after, "items"), input)
+}
+
+func parameters(op document) map[string]document {
+	result := make(map[string]document)
+	list, _ := op["parameters"].([]interface{})
+	for _, item := range list {
+		param, ok := item.(document)
+		if !ok {
+			continue
+		}
+
+		if ref, ok := param["$ref"]; ok {
+			result[fmt.Sprint(ref)] = param
+			continue
+		}
+
+		result[fmt.Sprintf("%v (%v)", param["name"], param["in"])] = param
+	}
+
+	return result
+}
+
+// bodySchema returns the schema of a request body or response, preferring JSON content
+func bodySchema(body document) document {
+	if schema := mapAt(body, "schema"); schema != nil {
+		return schema
+	}
+
+	content := mapAt(body, "content")
+	if schema := mapAt(content, "application/json", "schema"); schema != nil {
+		return schema
+	}
+
+	for _, mediaType := range sortedKeys(content, nil) {
+		if schema := mapAt(content, mediaType, "schema"); schema != nil {
+			return schema
+		}
+	}
+
+	return nil
+}
+
+func isRequired(doc document) bool {
+	required, _ := doc["required"].(bool)
+	return required
+}
+
+func required(schema document) map[string]bool {
+	result := make(map[string]bool)
+	list, _ := schema["required"].([]interface{})
+	for _, item := range list {
+		result[fmt.Sprint(item)] = true
+	}
+
+	return result
+}
+
+func describe(schema document) string {
+	if ref, ok := schema["$ref"]; ok {
+		return fmt.Sprint(ref)
+	}
+
+	return fmt.Sprintf("%v schema", schema["type"])
+}
+
+func mapAt(doc document, keys ...string) document {
+	for _, key := range keys {
+		next, ok := doc[key].(document)
+		if !ok {
+			return nil
+		}
+		doc = next
+	}
+
+	return doc
+}
+
+func sortedKeys(before, after interface{}) []string {
+	keys := make(map[string]bool)
+	for _, m := range []interface{}{before, after} {
+		switch val := m.(type) {
+		case document:
+			for key := range val {
+				keys[key] = true
+			}
+		case map[string]document:
+			for key := range val {
+				keys[key] = true
+			}
+		}
+	}
+
+	var result []string
+	for key := range keys {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func change(lvl BumpLevel, format string, args ...interface{}) Change {
+	return Change{
+		Source:      Source,
+		Level:       lvl,
+		Description: fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,174 @@
+package openapi_test
+
+import (
+	"github.com/Masterminds/semver/v3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/pkg/config"
+	"github.com/timotto/semver-bumper/pkg/gitrepo"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	. "github.com/timotto/semver-bumper/pkg/openapi"
+	. "github.com/timotto/semver-bumper/pkg/test/git_testbed"
+	"os"
+	"strings"
+)
+
+const baseSpec = `openapi: 3.0.0
+info:
+  title: pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      parameters:
+      - name: limit
+        in: query
+        schema:
+          type: integer
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Pet"
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Pet"
+      responses:
+        "201":
+          description: created
+components:
+  schemas:
+    Pet:
+      type: object
+      required:
+      - name
+      properties:
+        name:
+          type: string
+        tag:
+          type: string
+`
+
+const baseSchema = `{
+  "type": "object",
+  "required": ["id"],
+  "properties": {
+    "id": {"type": "string"},
+    "labels": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+var _ = Describe("Openapi", func() {
+	Describe("Diff", func() {
+		var expectOneChange = func(before, after string, expectedLevel BumpLevel, expectedDescription string) {
+			changes, err := Diff("spec.yaml", []byte(before), []byte(after))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes).To(HaveLen(1), "%v", changes)
+			Expect(changes[0].Source).To(Equal(Source))
+			Expect(changes[0].Level).To(Equal(expectedLevel))
+			Expect(changes[0].Description).To(Equal("spec.yaml: " + expectedDescription))
+		}
+
+		DescribeTable(
+			"classifies the changes of an OpenAPI document",
+			func(old, new string, expectedLevel BumpLevel, expectedDescription string) {
+				expectOneChange(baseSpec, replace(baseSpec, old, new), expectedLevel, expectedDescription)
+			},
+			Entry("removed endpoint", "    post:\n", "    x-post:\n",
+				BumpLevelMajor, "removed endpoint POST /pets"),
+			Entry("added endpoint", "components:\n", "  /stores:\n    get:\n      responses: {}\ncomponents:\n",
+				BumpLevelMinor, "added endpoint GET /stores"),
+			Entry("added required property", "      - name\n      properties:\n", "      - name\n      - owner\n      properties:\n        owner:\n          type: string\n",
+				BumpLevelMajor, "added required property owner of components.schemas.Pet"),
+			Entry("added optional property", "        tag:\n", "        owner:\n          type: string\n        tag:\n",
+				BumpLevelMinor, "added optional property owner of components.schemas.Pet"),
+			Entry("removed property", "        tag:\n          type: string\n", "",
+				BumpLevelMajor, "removed property tag of components.schemas.Pet"),
+			Entry("changed property type", "        tag:\n          type: string\n", "        tag:\n          type: integer\n",
+				BumpLevelMajor, "changed type of property tag of components.schemas.Pet from string to integer"),
+			Entry("made parameter required", "      - name: limit\n        in: query\n", "      - name: limit\n        in: query\n        required: true\n",
+				BumpLevelMajor, "made parameter limit (query) of GET /pets required"),
+			Entry("added optional parameter", "      responses:\n        \"200\":", "      - name: offset\n        in: query\n      responses:\n        \"200\":",
+				BumpLevelMinor, "added optional parameter offset (query) of GET /pets"),
+			Entry("added response", "          description: created\n", "          description: created\n        \"400\":\n          description: bad request\n",
+				BumpLevelMinor, "added POST /pets response 400"),
+		)
+
+		It("reads unquoted status codes", func() {
+			unquoted := strings.ReplaceAll(baseSpec, "\"200\":", "200:")
+			unquoted = strings.ReplaceAll(unquoted, "\"201\":", "201:")
+			Expect(unquoted).ToNot(ContainSubstring("\"20"))
+
+			expectOneChange(unquoted, replace(unquoted, "        201:\n          description: created\n", ""),
+				BumpLevelMajor, "removed POST /pets response 201")
+		})
+
+		It("ignores unchanged documents", func() {
+			changes, err := Diff("spec.yaml", []byte(baseSpec), []byte(baseSpec))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes).To(BeEmpty())
+		})
+
+		It("reads JSON schema documents", func() {
+			expectOneChange(baseSchema, strings.Replace(baseSchema, `"required": ["id"]`, `"required": ["id", "labels"]`, 1),
+				BumpLevelMajor, "made property labels of schema required")
+			expectOneChange(baseSchema, strings.Replace(baseSchema, `{"type": "string"}}`, `{"type": "integer"}}`, 1),
+				BumpLevelMajor, "changed type of items of property labels of schema from string to integer")
+		})
+
+		It("reports removed and added files", func() {
+			changes, err := Diff("spec.yaml", []byte(baseSpec), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes).To(Equal([]Change{{Source: Source, Level: BumpLevelMajor, Description: "spec.yaml: removed"}}))
+
+			changes, err = Diff("spec.yaml", nil, []byte(baseSpec))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes).To(Equal([]Change{{Source: Source, Level: BumpLevelMinor, Description: "spec.yaml: added"}}))
+		})
+
+		It("returns an error when a file cannot be parsed", func() {
+			_, err := Diff("spec.yaml", []byte(baseSpec), []byte("- a\nb: c"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Analyze", func() {
+		var bed *TestbedRepo
+		BeforeEach(CreateBeforeEach(os.TempDir(), &bed))
+		AfterEach(TeardownAfterEach(&bed))
+
+		It("compares the configured files of the tagged commit with HEAD", func() {
+			bed.
+				AddCommitWithContent("one", map[string]string{"api/openapi.yaml": baseSpec, "other.yaml": baseSpec}).
+				AddLightweightTag("1.0.0").
+				AddCommitWithContent("two", map[string]string{
+					"api/openapi.yaml": replace(baseSpec, "    post:\n      requestBody:", "    put:\n      requestBody:"),
+					"other.yaml":       "ignored: true\n",
+				})
+
+			cfg := &Options{}
+			Expect(cfg.Valid()).ToNot(HaveOccurred())
+			repo, err := gitrepo.NewGitRepo(cfg, bed.Path())
+			Expect(err).ToNot(HaveOccurred())
+
+			changes, err := NewAnalyzer(repo, []string{"api/openapi.yaml"}).Analyze(semver.MustParse("1.0.0"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes).To(Equal([]Change{
+				{Source: Source, Level: BumpLevelMinor, Description: "api/openapi.yaml: added endpoint PUT /pets"},
+				{Source: Source, Level: BumpLevelMajor, Description: "api/openapi.yaml: removed endpoint POST /pets"},
+			}))
+		})
+	})
+})
+
+func replace(doc, old, new string) string {
+	Expect(doc).To(ContainSubstring(old))
+	return strings.Replace(doc, old, new, 1)
+}
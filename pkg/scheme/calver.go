@@ -0,0 +1,132 @@
+package scheme
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	yearFull        = "YYYY"
+	yearShort       = "YY"
+	yearZeroPadded  = "0Y"
+	monthShort      = "MM"
+	monthZeroPadded = "0M"
+	micro           = "MICRO"
+)
+
+// CalVer is calendar versioning with a format like "YYYY.MM.MICRO", the first release of a month has micro 0
+// and every other release of the month increments the micro, regardless of the bump level
+type CalVer struct {
+	format      string
+	year, month string
+}
+
+// NewCalVer returns the calendar versioning scheme with the given format,
+// the year is YYYY, YY, or 0Y, the month is MM or 0M, followed by MICRO
+func NewCalVer(format string) (*CalVer, error) {
+	parts := strings.Split(format, ".")
+	if len(parts) != 3 || !oneOf(parts[0], yearFull, yearShort, yearZeroPadded) || !oneOf(parts[1], monthShort, monthZeroPadded) || parts[2] != micro {
+		return nil, fmt.Errorf("invalid version scheme %v: expected %v or a calendar versioning format like YYYY.MM.MICRO or YY.0M.MICRO", format, SemVerName)
+	}
+
+	return &CalVer{format: format, year: parts[0], month: parts[1]}, nil
+}
+
+func (c *CalVer) Parse(value string) (*semver.Version, error) {
+	core, rest := value, ""
+	if i := strings.IndexAny(value, "-+"); i >= 0 {
+		core, rest = value[:i], value[i:]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 || !segmentMatches(c.year, parts[0]) || !segmentMatches(c.month, parts[1]) || !segmentMatches(micro, parts[2]) {
+		return nil, fmt.Errorf("%v does not match %v", value, c.format)
+	}
+
+	if month, _ := strconv.Atoi(parts[1]); month < 1 || month > 12 {
+		return nil, fmt.Errorf("%v has an invalid month", value)
+	}
+
+	var numbers []string
+	for _, part := range parts {
+		n, _ := strconv.ParseUint(part, 10, 64)
+		numbers = append(numbers, strconv.FormatUint(n, 10))
+	}
+
+	return semver.StrictNewVersion(strings.Join(numbers, ".") + rest)
+}
+
+func (c *CalVer) Compare(a, b *semver.Version) int {
+	return a.Compare(b)
+}
+
+func (c *CalVer) Next(latest *semver.Version, lvl BumpLevel, at time.Time) *semver.Version {
+	if latest != nil && lvl == BumpLevelNone {
+		return latest
+	}
+
+	at = at.UTC()
+	year := at.Year()
+	if c.year != yearFull {
+		year %= 100
+	}
+
+	next := semver.MustParse(fmt.Sprintf("%d.%d.0", year, at.Month()))
+	if latest != nil && !next.GreaterThan(latest) {
+		inc := latest.IncPatch()
+		return &inc
+	}
+
+	return next
+}
+
+func (c *CalVer) String(v *semver.Version) string {
+	result := fmt.Sprintf("%v.%v.%d", segment(c.year, v.Major()), segment(c.month, v.Minor()), v.Patch())
+	if v.Prerelease() != "" {
+		result += "-" + v.Prerelease()
+	}
+	if v.Metadata() != "" {
+		result += "+" + v.Metadata()
+	}
+
+	return result
+}
+
+func segmentMatches(token, value string) bool {
+	if value == "" || strings.Trim(value, "0123456789") != "" {
+		return false
+	}
+
+	switch token {
+	case yearFull:
+		return len(value) == 4 && value[0] != '0'
+	case yearZeroPadded, monthZeroPadded:
+		return len(value) == 2
+	case yearShort, monthShort:
+		return len(value) <= 2 && (len(value) == 1 || value[0] != '0')
+	default:
+		return len(value) == 1 || value[0] != '0'
+	}
+}
+
+func segment(token string, value uint64) string {
+	if token == yearZeroPadded || token == monthZeroPadded {
+		return fmt.Sprintf("%02d", value)
+	}
+
+	return strconv.FormatUint(value, 10)
+}
+
+func oneOf(value string, candidates ...string) bool {
+	for _, candidate := range candidates {
+		if value == candidate {
+			return true
+		}
+	}
+
+	return false
+}
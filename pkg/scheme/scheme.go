@@ -0,0 +1,41 @@
+// Package scheme implements the version schemes, semantic versioning and calendar versioning.
+//
+// Versions of both schemes are semver.Version values, a calendar version has the year as major,
+// the month as minor, and the micro as patch version, so that prereleases work the same.
+package scheme
+
+import (
+	"github.com/Masterminds/semver/v3"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"time"
+)
+
+// SemVerName is the name of the semantic versioning scheme, any other name is a calendar versioning format
+const SemVerName = "semver"
+
+// Scheme parses, compares, bumps, and formats the versions of a versioning scheme
+type Scheme interface {
+	// Parse parses a version without the tag prefix, it fails for versions not matching the scheme
+	Parse(value string) (*semver.Version, error)
+	// Compare returns -1, 0, or 1 if a is lower than, equal to, or greater than b
+	Compare(a, b *semver.Version) int
+	// Next returns the release following the latest release for a bump level at the given time,
+	// the initial release if latest is nil, and latest if there is nothing to bump
+	Next(latest *semver.Version, lvl BumpLevel, at time.Time) *semver.Version
+	// String formats a version of the scheme
+	String(v *semver.Version) string
+}
+
+// New returns the scheme with the given name, semantic versioning if the name is empty
+func New(name string) (Scheme, error) {
+	if IsSemVer(name) {
+		return SemVer{}, nil
+	}
+
+	return NewCalVer(name)
+}
+
+// IsSemVer returns true if the name is the one of semantic versioning or empty
+func IsSemVer(name string) bool {
+	return name == "" || name == SemVerName
+}
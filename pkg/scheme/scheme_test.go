@@ -0,0 +1,94 @@
+package scheme_test
+
+import (
+	"github.com/Masterminds/semver/v3"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	. "github.com/timotto/semver-bumper/pkg/scheme"
+	"time"
+)
+
+var _ = Describe("Scheme", func() {
+	october := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	var mustNew = func(name string) Scheme {
+		s, err := New(name)
+		Expect(err).ToNot(HaveOccurred())
+		return s
+	}
+
+	Describe("New", func() {
+		It("returns semantic versioning by default", func() {
+			Expect(mustNew("")).To(Equal(SemVer{}))
+			Expect(mustNew(SemVerName)).To(Equal(SemVer{}))
+		})
+
+		DescribeTable("rejects invalid formats", func(format string) {
+			_, err := New(format)
+			Expect(err).To(MatchError(ContainSubstring("invalid version scheme")))
+		},
+			Entry("unknown name", "calver"),
+			Entry("missing micro", "YYYY.MM"),
+			Entry("month first", "MM.YYYY.MICRO"),
+		)
+	})
+
+	Describe("SemVer", func() {
+		It("bumps the segment of the level", func() {
+			latest := semver.MustParse("1.2.3")
+			Expect(SemVer{}.Next(latest, BumpLevelMajor, october).String()).To(Equal("2.0.0"))
+			Expect(SemVer{}.Next(latest, BumpLevelMinor, october).String()).To(Equal("1.3.0"))
+			Expect(SemVer{}.Next(latest, BumpLevelPatch, october).String()).To(Equal("1.2.4"))
+			Expect(SemVer{}.Next(latest, BumpLevelNone, october)).To(BeIdenticalTo(latest))
+		})
+	})
+
+	Describe("CalVer", func() {
+		DescribeTable("Parse and String", func(format, value string, expected string) {
+			v, err := mustNew(format).Parse(value)
+			if expected == "" {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(v.String()).To(Equal(expected))
+			Expect(mustNew(format).String(v)).To(Equal(value))
+		},
+			Entry("full year", "YYYY.MM.MICRO", "2026.10.3", "2026.10.3"),
+			Entry("short month", "YYYY.MM.MICRO", "2026.9.0", "2026.9.0"),
+			Entry("zero padded month", "YY.0M.MICRO", "26.09.0", "26.9.0"),
+			Entry("zero padded year", "0Y.MM.MICRO", "05.9.1", "5.9.1"),
+			Entry("prerelease", "YY.0M.MICRO", "26.10.0-rc.1", "26.10.0-rc.1"),
+			Entry("semver", "YYYY.MM.MICRO", "1.2.3", ""),
+			Entry("missing padding", "YY.0M.MICRO", "26.9.0", ""),
+			Entry("unexpected padding", "YYYY.MM.MICRO", "2026.09.0", ""),
+			Entry("invalid month", "YYYY.MM.MICRO", "2026.13.0", ""),
+			Entry("invalid prerelease", "YYYY.MM.MICRO", "2026.10.0-rc.01", ""),
+		)
+
+		Describe("Next", func() {
+			calver := mustNew("YY.0M.MICRO")
+
+			It("starts with micro 0 of the current month", func() {
+				Expect(calver.String(calver.Next(nil, BumpLevelNone, october))).To(Equal("26.10.0"))
+				Expect(calver.String(calver.Next(semver.MustParse("26.9.4"), BumpLevelMajor, october))).To(Equal("26.10.0"))
+			})
+
+			It("increments the micro within the same month", func() {
+				Expect(calver.String(calver.Next(semver.MustParse("26.10.2"), BumpLevelPatch, october))).To(Equal("26.10.3"))
+			})
+
+			It("increments the micro of a latest release from the future", func() {
+				Expect(calver.String(calver.Next(semver.MustParse("26.11.0"), BumpLevelPatch, october))).To(Equal("26.11.1"))
+			})
+
+			It("returns the latest release if there is nothing to bump", func() {
+				latest := semver.MustParse("26.9.4")
+				Expect(calver.Next(latest, BumpLevelNone, october)).To(BeIdenticalTo(latest))
+			})
+		})
+	})
+})
@@ -0,0 +1,44 @@
+package scheme
+
+import (
+	"github.com/Masterminds/semver/v3"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"time"
+)
+
+// SemVer is semantic versioning, the bump level selects the version segment to increment
+type SemVer struct{}
+
+var initialSemVer = semver.MustParse("1.0.0")
+
+func (SemVer) Parse(value string) (*semver.Version, error) {
+	return semver.StrictNewVersion(value)
+}
+
+func (SemVer) Compare(a, b *semver.Version) int {
+	return a.Compare(b)
+}
+
+func (SemVer) Next(latest *semver.Version, lvl BumpLevel, _ time.Time) *semver.Version {
+	if latest == nil {
+		return initialSemVer
+	}
+
+	var next semver.Version
+	switch lvl {
+	case BumpLevelMajor:
+		next = latest.IncMajor()
+	case BumpLevelMinor:
+		next = latest.IncMinor()
+	case BumpLevelPatch:
+		next = latest.IncPatch()
+	default:
+		return latest
+	}
+
+	return &next
+}
+
+func (SemVer) String(v *semver.Version) string {
+	return v.String()
+}
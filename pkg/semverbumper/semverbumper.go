@@ -0,0 +1,103 @@
+// Package semverbumper computes the next version of a git repository like the semver-bumper command.
+package semverbumper
+
+import (
+	"context"
+	"github.com/Masterminds/semver/v3"
+	"github.com/timotto/semver-bumper/pkg/apidiff"
+	"github.com/timotto/semver-bumper/pkg/bumper"
+	"github.com/timotto/semver-bumper/pkg/config"
+	"github.com/timotto/semver-bumper/pkg/estimator"
+	"github.com/timotto/semver-bumper/pkg/gitrepo"
+	"github.com/timotto/semver-bumper/pkg/logger"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	"github.com/timotto/semver-bumper/pkg/openapi"
+	"time"
+)
+
+type (
+	// Options are the input of Compute, the environment and config files are not read
+	Options struct {
+		// Path is the path of the git repository, the working directory if empty
+		Path string
+		// Config is the configuration, missing values are set to their defaults
+		Config config.Options
+		// Now returns the date of a calendar version, time.Now if nil
+		Now func() time.Time
+		// Logger receives the tags, the commit range, the filter decisions, and the keyword matches, nil discards them
+		Logger *logger.Logger
+	}
+
+	// Result is the next version with the previous versions, the bump level, the commits, and the reasons
+	Result = bumper.Result
+
+	// Reason is the level of a commit or a change detected by an analyzer
+	Reason = bumper.Reason
+
+	// contextAnalyzer stops before each analysis once the context is done
+	contextAnalyzer struct {
+		bumper.Analyzer
+		ctx context.Context
+	}
+)
+
+// ErrNothingToRelease is returned along with a result with the latest release, or the latest prerelease
+// when bumping a prerelease, if nothing justifies a new release
+var ErrNothingToRelease = bumper.ErrNothingToRelease
+
+// Compute returns the next version of the git repository, it stops reading the tags, walking the history,
+// and running the analyzers once the context is done
+func Compute(ctx context.Context, opts Options) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	conf := opts.Config
+	if err := conf.Valid(); err != nil {
+		return nil, err
+	}
+	if opts.Now != nil {
+		conf.WithNow(opts.Now)
+	}
+	conf.WithLogger(opts.Logger)
+
+	path := opts.Path
+	if path == "" {
+		path = "."
+	}
+
+	repo, err := gitrepo.NewGitRepo(&conf, path)
+	if err != nil {
+		return nil, err
+	}
+	repo.WithLogger(opts.Logger).WithContext(ctx)
+
+	var analyzers []bumper.Analyzer
+	for _, analyzer := range Analyzers(&conf, repo) {
+		analyzers = append(analyzers, contextAnalyzer{Analyzer: analyzer, ctx: ctx})
+	}
+
+	return bumper.Compute(&conf, repo, estimator.NewEstimator(&conf).WithLogger(opts.Logger), analyzers...)
+}
+
+// Analyzers returns the analyzers enabled by the configuration
+func Analyzers(conf *config.Options, repo *gitrepo.Gitrepo) []bumper.Analyzer {
+	var result []bumper.Analyzer
+	if conf.GoApi {
+		result = append(result, apidiff.NewAnalyzer(repo))
+	}
+
+	if len(conf.OpenApi) > 0 {
+		result = append(result, openapi.NewAnalyzer(repo, conf.OpenApi))
+	}
+
+	return result
+}
+
+func (a contextAnalyzer) Analyze(since *semver.Version) ([]Change, error) {
+	if err := a.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return a.Analyzer.Analyze(since)
+}
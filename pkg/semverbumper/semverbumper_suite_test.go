@@ -0,0 +1,13 @@
+package semverbumper_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestSemverbumper(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Semverbumper Suite")
+}
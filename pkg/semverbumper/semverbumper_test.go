@@ -0,0 +1,80 @@
+package semverbumper_test
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/timotto/semver-bumper/pkg/config"
+	. "github.com/timotto/semver-bumper/pkg/model"
+	. "github.com/timotto/semver-bumper/pkg/semverbumper"
+	. "github.com/timotto/semver-bumper/pkg/test/git_testbed"
+	"os"
+	"time"
+)
+
+var _ = Describe("Compute", func() {
+	var bed *TestbedRepo
+	BeforeEach(CreateBeforeEach(os.TempDir(), &bed))
+	AfterEach(TeardownAfterEach(&bed))
+
+	BeforeEach(func() {
+		bed.
+			AddCommits("initial").
+			AddLightweightTag("v1.2.3").
+			AddCommits("feat: expected feature")
+	})
+
+	It("computes the next version with the defaults", func() {
+		result, err := Compute(context.Background(), Options{Path: bed.Path(), Config: config.Options{TagPrefix: "v"}})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Version.String()).To(Equal("1.3.0"))
+		Expect(result.LatestRelease.String()).To(Equal("1.2.3"))
+		Expect(result.Level).To(Equal(BumpLevelMinor))
+		Expect(result.Reasons).To(HaveLen(1))
+		Expect(result.Reasons[0].Description).To(Equal("feat: expected feature"))
+	})
+
+	It("does not change the given configuration", func() {
+		opts := Options{Path: bed.Path(), Config: config.Options{TagPrefix: "v"}}
+
+		_, err := Compute(context.Background(), opts)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(opts.Config.KeywordsMinor).To(BeNil())
+	})
+
+	It("returns ErrNothingToRelease with the latest release", func() {
+		bed.AddLightweightTag("v1.3.0")
+
+		result, err := Compute(context.Background(), Options{Path: bed.Path(), Config: config.Options{TagPrefix: "v"}})
+
+		Expect(err).To(MatchError(ErrNothingToRelease))
+		Expect(result.Version.String()).To(Equal("1.3.0"))
+	})
+
+	It("computes a calendar version at the given time", func() {
+		now := func() time.Time { return time.Date(2024, time.March, 15, 12, 0, 0, 0, time.UTC) }
+		bed.AddLightweightTag("cal-20.01.4").AddCommits("fix: expected fix")
+
+		result, err := Compute(context.Background(), Options{Path: bed.Path(), Now: now, Config: config.Options{TagPrefix: "cal-", Scheme: "YY.0M.MICRO"}})
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Version.String()).To(Equal("24.3.0"))
+	})
+
+	It("returns the problems of an invalid configuration", func() {
+		_, err := Compute(context.Background(), Options{Path: bed.Path(), Config: config.Options{InitialVersion: "invalid"}})
+
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("stops if the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := Compute(ctx, Options{Path: bed.Path()})
+
+		Expect(err).To(MatchError(context.Canceled))
+	})
+})
@@ -3,10 +3,14 @@ package git_testbed
 import (
 	"fmt"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	. "github.com/onsi/gomega"
 	"os"
 	"path"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -14,6 +18,7 @@ type TestbedRepo struct {
 	path string
 	repo *git.Repository
 	time time.Time
+	step time.Duration
 }
 
 func CreateBeforeEach(dir string, p **TestbedRepo) func() {
@@ -51,9 +56,17 @@ func NewTestbedRepo(baseDirectory string) *TestbedRepo {
 		path: dir,
 		repo: repo,
 		time: time.Now().Add(-240 * time.Hour),
+		step: time.Second,
 	}
 }
 
+// WithFrozenTime gives the commits added next the same timestamp
+func (b *TestbedRepo) WithFrozenTime() *TestbedRepo {
+	b.step = 0
+
+	return b
+}
+
 func (b *TestbedRepo) Teardown() {
 	Expect(b.Path()).To(HavePrefix(os.TempDir()))
 	Expect(b.Path()).ToNot(Equal(os.TempDir()))
@@ -77,18 +90,33 @@ func (b *TestbedRepo) AddCommit(message string) *TestbedRepo {
 }
 
 func (b *TestbedRepo) AddCommitAt(filename, message string) *TestbedRepo {
-	fullPath := path.Join(b.path, filename)
-	fullDir := path.Dir(fullPath)
-	Expect(os.MkdirAll(fullDir, 0755)).ToNot(HaveOccurred())
+	return b.AddCommitsAt(message, filename)
+}
 
-	err := os.WriteFile(fullPath, []byte("some content"), 0640)
-	Expect(err).ToNot(HaveOccurred())
+func (b *TestbedRepo) AddCommitsAt(message string, filenames ...string) *TestbedRepo {
+	files := make(map[string]string)
+	for _, filename := range filenames {
+		files[filename] = "some content"
+	}
 
+	return b.AddCommitWithContent(message, files)
+}
+
+func (b *TestbedRepo) AddCommitWithContent(message string, files map[string]string) *TestbedRepo {
 	w, err := b.repo.Worktree()
 	Expect(err).ToNot(HaveOccurred())
 
-	_, err = w.Add(filename)
-	Expect(err).ToNot(HaveOccurred())
+	for filename, content := range files {
+		fullPath := path.Join(b.path, filename)
+		fullDir := path.Dir(fullPath)
+		Expect(os.MkdirAll(fullDir, 0755)).ToNot(HaveOccurred())
+
+		err := os.WriteFile(fullPath, []byte(content), 0640)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = w.Add(filename)
+		Expect(err).ToNot(HaveOccurred())
+	}
 
 	commit, err := w.Commit(message, &git.CommitOptions{
 		Author:    b.aSignature(),
@@ -102,6 +130,31 @@ func (b *TestbedRepo) AddCommitAt(filename, message string) *TestbedRepo {
 	return b
 }
 
+// AddMerge adds the commits on a branch off HEAD and merges the branch with the given message
+func (b *TestbedRepo) AddMerge(message string, branchMessages ...string) *TestbedRepo {
+	head, err := b.repo.Head()
+	Expect(err).ToNot(HaveOccurred())
+
+	b.AddCommits(branchMessages...)
+	branch, err := b.repo.Head()
+	Expect(err).ToNot(HaveOccurred())
+
+	w, err := b.repo.Worktree()
+	Expect(err).ToNot(HaveOccurred())
+
+	// the soft reset keeps the changes of the branch in the index for the merge commit
+	Expect(w.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.SoftReset})).ToNot(HaveOccurred())
+
+	_, err = w.Commit(message, &git.CommitOptions{
+		Author:    b.aSignature(),
+		Committer: b.aSignature(),
+		Parents:   []plumbing.Hash{head.Hash(), branch.Hash()},
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	return b
+}
+
 func (b *TestbedRepo) AddAnnotatedTag(tag string) *TestbedRepo {
 	head, err := b.repo.Head()
 	Expect(err).ToNot(HaveOccurred())
@@ -125,6 +178,100 @@ func (b *TestbedRepo) AddLightweightTag(tag string) *TestbedRepo {
 	return b
 }
 
+// AddGeneratedCommits adds n commits directly to the object storage, which is a lot faster than AddCommits for
+// large histories. The tree of every commit is the single file "generated-file" with new content,
+// the worktree is not touched.
+// The commit messages repeat the given messages, the tag function returns the name of the lightweight tag
+// for the i-th commit or an empty string for no tag.
+func (b *TestbedRepo) AddGeneratedCommits(n int, messages []string, tag func(i int) string) *TestbedRepo {
+	return b.AddGeneratedCommitsAt(n, messages, []string{"generated-file"}, tag)
+}
+
+// AddGeneratedCommitsAt is AddGeneratedCommits with the i-th commit changing the file at the i-th of the given
+// paths, repeating them. The tree of every commit has the files of all previous generated commits.
+func (b *TestbedRepo) AddGeneratedCommitsAt(n int, messages, filenames []string, tag func(i int) string) *TestbedRepo {
+	head, err := b.repo.Storer.Reference(plumbing.HEAD)
+	Expect(err).ToNot(HaveOccurred())
+
+	var parents []plumbing.Hash
+	if current, err := b.repo.Head(); err == nil {
+		parents = []plumbing.Hash{current.Hash()}
+	}
+
+	files := make(map[string]plumbing.Hash)
+	for i := 0; i < n; i++ {
+		blob := b.repo.Storer.NewEncodedObject()
+		blob.SetType(plumbing.BlobObject)
+		w, err := blob.Writer()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = fmt.Fprintf(w, "generated content %d", i)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(w.Close()).ToNot(HaveOccurred())
+		blobHash, err := b.repo.Storer.SetEncodedObject(blob)
+		Expect(err).ToNot(HaveOccurred())
+
+		files[filenames[i%len(filenames)]] = blobHash
+		tree := b.storeTree(files)
+
+		signature := b.aSignature()
+		hash := b.storeObject(&object.Commit{
+			Author:       *signature,
+			Committer:    *signature,
+			Message:      messages[i%len(messages)],
+			TreeHash:     tree,
+			ParentHashes: parents,
+		})
+		parents = []plumbing.Hash{hash}
+
+		if name := tag(i); name != "" {
+			ref := plumbing.NewHashReference(plumbing.NewTagReferenceName(name), hash)
+			Expect(b.repo.Storer.SetReference(ref)).ToNot(HaveOccurred())
+		}
+	}
+
+	if len(parents) > 0 {
+		ref := plumbing.NewHashReference(head.Target(), parents[0])
+		Expect(b.repo.Storer.SetReference(ref)).ToNot(HaveOccurred())
+	}
+
+	return b
+}
+
+// storeTree stores the tree of the given files with their subtrees and returns its hash
+func (b *TestbedRepo) storeTree(files map[string]plumbing.Hash) plumbing.Hash {
+	var entries []object.TreeEntry
+	subtrees := make(map[string]map[string]plumbing.Hash)
+	for filename, hash := range files {
+		parts := strings.SplitN(filename, "/", 2)
+		if len(parts) == 1 {
+			entries = append(entries, object.TreeEntry{Name: filename, Mode: filemode.Regular, Hash: hash})
+			continue
+		}
+
+		if subtrees[parts[0]] == nil {
+			subtrees[parts[0]] = make(map[string]plumbing.Hash)
+		}
+		subtrees[parts[0]][parts[1]] = hash
+	}
+
+	for name, subtree := range subtrees {
+		entries = append(entries, object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: b.storeTree(subtree)})
+	}
+
+	// git sorts the entries by name, with a trailing slash for the directories
+	sortName := func(entry object.TreeEntry) string {
+		if entry.Mode == filemode.Dir {
+			return entry.Name + "/"
+		}
+		return entry.Name
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return sortName(entries[i]) < sortName(entries[j])
+	})
+
+	return b.storeObject(&object.Tree{Entries: entries})
+}
+
 func (b *TestbedRepo) Commits() []*object.Commit {
 	iter, err := b.repo.Log(&git.LogOptions{
 		Order: git.LogOrderCommitterTime,
@@ -142,6 +289,16 @@ func (b *TestbedRepo) Commits() []*object.Commit {
 	return result
 }
 
+func (b *TestbedRepo) storeObject(o object.Object) plumbing.Hash {
+	obj := b.repo.Storer.NewEncodedObject()
+	Expect(o.Encode(obj)).ToNot(HaveOccurred())
+
+	hash, err := b.repo.Storer.SetEncodedObject(obj)
+	Expect(err).ToNot(HaveOccurred())
+
+	return hash
+}
+
 func (b *TestbedRepo) aSignature() *object.Signature {
 	return &object.Signature{
 		Name:  "A Name",
@@ -152,7 +309,7 @@ func (b *TestbedRepo) aSignature() *object.Signature {
 
 func (b *TestbedRepo) nextTimeNow() time.Time {
 	now := b.time
-	b.time = now.Add(time.Second)
+	b.time = now.Add(b.step)
 
 	return now
 }
@@ -112,6 +112,46 @@ var _ = Describe("GitTestbed", func() {
 		})
 	})
 
+	Describe("AddCommitsAt", func() {
+		It("adds a commit with multiple specific filenames", func() {
+			message := "commit message"
+			uut.AddCommitsAt(message, "file-a", "some-directory/file-b")
+			result := runGit("show", "--name-only", "--format=%s")
+			result.ExpectSuccess()
+			result.ExpectOutput(message + "\n\nfile-a\nsome-directory/file-b\n")
+		})
+	})
+
+	Describe("AddCommitWithContent", func() {
+		It("adds a commit with files with specific content", func() {
+			message := "commit message"
+			uut.AddCommitWithContent(message, map[string]string{"some-directory/file": "expected content"})
+			runGit("log", "--format=%s").
+				ExpectSuccess().
+				ExpectOutput(message + "\n")
+
+			content, err := os.ReadFile(path.Join(uut.Path(), "some-directory", "file"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(Equal("expected content"))
+		})
+	})
+
+	Describe("AddMerge", func() {
+		It("merges the commits of a branch", func() {
+			uut.AddCommits("first").AddMerge("merge", "branch-1", "branch-2")
+
+			runGit("log", "--first-parent", "--format=%s").
+				ExpectSuccess().
+				ExpectOutput("merge\nfirst\n")
+			runGit("log", "--format=%s", "HEAD^2").
+				ExpectSuccess().
+				ExpectOutput("branch-2\nbranch-1\nfirst\n")
+			runGit("status", "--porcelain").
+				ExpectSuccess().
+				ExpectOutput("")
+		})
+	})
+
 	Describe("AddLightweightTag", func() {
 		It("adds a lightweight tag to the head", func() {
 			firstMessage := "first commit message"
@@ -154,6 +194,53 @@ var _ = Describe("GitTestbed", func() {
 		})
 	})
 
+	Describe("AddGeneratedCommits", func() {
+		It("adds the commits with repeating messages and tags on top of HEAD", func() {
+			uut.AddCommits("first commit message")
+			uut.AddGeneratedCommits(5, []string{"one", "two"}, func(i int) string {
+				if i%2 == 0 {
+					return fmt.Sprintf("v%d", i)
+				}
+				return ""
+			})
+
+			runGit("log", "--format=%s").
+				ExpectSuccess().
+				ExpectOutput("one\ntwo\none\ntwo\none\nfirst commit message\n")
+
+			runGit("tag", "--contains", "HEAD").
+				ExpectSuccess().
+				ExpectOutput("v4\n")
+
+			runGit("tag").
+				ExpectSuccess().
+				ExpectOutput("v0\nv2\nv4\n")
+
+			runGit("show", "HEAD:generated-file").
+				ExpectSuccess().
+				ExpectOutput("generated content 4")
+		})
+	})
+
+	Describe("AddGeneratedCommitsAt", func() {
+		It("changes the files at the given paths in turn", func() {
+			uut.AddGeneratedCommitsAt(3, []string{"one"}, []string{"docs/guide/index.md", "src/main.go"}, func(int) string {
+				return ""
+			})
+
+			runGit("log", "--format=", "--name-only").
+				ExpectSuccess().
+				ExpectOutput("docs/guide/index.md\nsrc/main.go\ndocs/guide/index.md\n")
+
+			runGit("show", "HEAD:src/main.go").
+				ExpectSuccess().
+				ExpectOutput("generated content 1")
+
+			runGit("fsck", "--strict").
+				ExpectSuccess()
+		})
+	})
+
 	Describe("Commits", func() {
 		It(`returns all the commits like "git log --format=oneline"`, func() {
 			// given
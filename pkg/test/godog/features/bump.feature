@@ -28,7 +28,7 @@ Feature: add release tags to a git repository
 
     Given there is a commit "feat: feature 2"
     And there is a commit "BREAKING CHANGE: all new"
-    When I run semver-bumper -t v -0 1.2.3
+    When I run semver-bumper -t v -0 1.2.3 --allow-major
     Then I see the version 2.0.0
     And I tag the git with v2.0.0
 
@@ -6,9 +6,9 @@ Feature: get help
   Scenario: Display help with long option
     When I run semver-bumper --help
     Then I see the help page
-    And the exit code is 1
+    And the exit code is 0
 
   Scenario: Display help with short option
     When I run semver-bumper -h
     Then I see the help page
-    And the exit code is 1
+    And the exit code is 0
@@ -8,10 +8,19 @@ Feature: bump release version
     And there is a commit "go live" with the tag 2.0.0
     And there is a commit "BREAKING CHANGE: everything is new"
 
-    When I run semver-bumper
+    When I run semver-bumper --allow-major
 
     Then I see the version 3.0.0
 
+  Scenario: Refuse major level without --allow-major
+    Given there is a directory with a git repository
+    And there is a commit "go live" with the tag 2.0.0
+    And there is a commit "BREAKING CHANGE: everything is new"
+
+    When I run semver-bumper
+
+    Then the exit code is 1
+
   Scenario: Minor major level
     Given there is a directory with a git repository
     And there is a commit "go live" with the tag 2.0.0